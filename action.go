@@ -0,0 +1,217 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// describeAction describes the action in uri, returning the describe and the ids in uri.  Static actions may
+// not have ids, and non-static actions must have ids
+func (cinp *CInP) describeAction(ctx context.Context, uri string) (*Describe, []string, error) {
+	u := cinp.uriFor(uri)
+	ns, model, action, ids, _, err := u.Split(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if action == "" {
+		return nil, nil, fmt.Errorf("'%s' is not an action URI", uri)
+	}
+
+	actionURI, err := u.Build(ns, model, action, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	describe, describeType, err := cinp.Describe(ctx, actionURI)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if describeType != "Action" {
+		return nil, nil, fmt.Errorf("expected '%s' to be a 'Action' got '%s'", actionURI, describeType)
+	}
+
+	if describe.Static && len(ids) > 0 {
+		return nil, nil, fmt.Errorf("action '%s' is static, it can not be called on object(s) '%s'", actionURI, uri)
+	}
+
+	if !describe.Static && len(ids) == 0 {
+		return nil, nil, fmt.Errorf("action '%s' is not static, it must be called on object(s)", actionURI)
+	}
+
+	return describe, ids, nil
+}
+
+// decodeReturn decodes the raw result of a call into result as returnType.  For Model returns, result can be
+// a *string or *[]string for the URI(s), or a Object, *Object or *[]Object which are fetched with Get
+func (cinp *CInP) decodeReturn(ctx context.Context, returnType FieldParamater, raw json.RawMessage, result interface{}) error {
+	var value interface{}
+	if err := decodeJSON(raw, &value); err != nil {
+		return fmt.Errorf("unable to parse result '%s'", err)
+	}
+
+	if returnType.Type == "" || result == nil || value == nil {
+		return nil
+	}
+
+	returnType.Name = "return"
+	if err := checkValue(returnType, value); err != nil {
+		return fmt.Errorf("unexpected result: %w", err)
+	}
+
+	switch returnType.Type {
+	case "Model":
+		var uriList []string
+		if returnType.IsArray {
+			if err := json.Unmarshal(raw, &uriList); err != nil {
+				return err
+			}
+		} else {
+			uriList = []string{value.(string)}
+		}
+
+		switch result.(type) {
+		case *Object, Object:
+			if returnType.IsArray {
+				return fmt.Errorf("return-type is a list of '%s', expected a *[]Object", returnType.URI)
+			}
+		}
+
+		switch target := result.(type) {
+		case *Object:
+			object, err := cinp.Get(ctx, uriList[0])
+			if err != nil {
+				return err
+			}
+			*target = *object
+			return nil
+
+		case *[]Object:
+			*target = make([]Object, len(uriList))
+			for i, uri := range uriList {
+				object, err := cinp.Get(ctx, uri)
+				if err != nil {
+					return err
+				}
+				(*target)[i] = *object
+			}
+			return nil
+
+		case Object:
+			return cinp.GetInto(ctx, uriList[0], target)
+		}
+
+	case "DateTime":
+		switch target := result.(type) {
+		case *time.Time:
+			var err error
+			*target, err = parseDateTime(value.(string))
+			return err
+
+		case *[]time.Time:
+			list := []string{}
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return err
+			}
+			*target = make([]time.Time, len(list))
+			for i, item := range list {
+				var err error
+				if (*target)[i], err = parseDateTime(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	return json.Unmarshal(raw, result)
+}
+
+// CallAction calls the action in uri after checking args against the action's paramaters, and that static
+// actions are called on the model and non-static actions on a object.  The result is checked against the
+// action's return-type and decoded into result.  Model results can be decoded into a *string or *[]string for
+// the URI(s), or into a Object, *Object or *[]Object which are fetched with Get.  DateTime results can be decoded
+// into a *time.Time or *[]time.Time.  result can be nil if the return value is not needed.
+func (cinp *CInP) CallAction(ctx context.Context, uri string, args map[string]interface{}, result interface{}) error {
+	describe, ids, err := cinp.describeAction(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 1 {
+		return fmt.Errorf("'%s' is more than one object, use CallMultiAs", uri)
+	}
+
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	if err := checkValues(describe.Paramaters, args); err != nil {
+		return fmt.Errorf("action '%s': %w", describe.Path, err)
+	}
+
+	raw := json.RawMessage{}
+	if err := cinp.Call(ctx, uri, &args, &raw); err != nil {
+		return err
+	}
+
+	if len(raw) == 0 {
+		return nil
+	}
+
+	return cinp.decodeReturn(ctx, describe.ReturnType, raw, result)
+}
+
+// CallResult is the result of calling a action on one of the objects of a multi-object call
+type CallResult[T any] struct {
+	Value T
+	Err   error // set if the result for this object could not be decoded
+}
+
+// CallMultiAs calls the action in uri on each of it's objects, see CallAction for the checking of the args.  The
+// result for each object is decoded into a T, keyed by the object's URI, a result that fails to decode has Err
+// set and does not fail the others.
+func CallMultiAs[T any](ctx context.Context, cinp *CInP, uri string, args map[string]interface{}) (map[string]CallResult[T], error) {
+	describe, _, err := cinp.describeAction(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	if err := checkValues(describe.Paramaters, args); err != nil {
+		return nil, fmt.Errorf("action '%s': %w", describe.Path, err)
+	}
+
+	rawMap := map[string]json.RawMessage{}
+	headers := map[string]string{"Multi-Object": "True"}
+	cinp.log.Info("CALL(multi)", "uri", uri)
+	code, headers, err := cinp.request(ctx, "CALL", uri, &args, &rawMap, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if code != 200 {
+		return nil, fmt.Errorf("unexpected HTTP code '%d'", code)
+	}
+
+	if headers["Multi-Object"] != httpTrue {
+		return nil, fmt.Errorf("no multi result detected")
+	}
+
+	result := make(map[string]CallResult[T], len(rawMap))
+	for objectURI, raw := range rawMap {
+		item := CallResult[T]{}
+		if err := cinp.decodeReturn(ctx, describe.ReturnType, raw, &item.Value); err != nil {
+			item.Err = fmt.Errorf("result for '%s': %w", objectURI, err)
+		}
+		result[objectURI] = item
+	}
+
+	return result, nil
+}
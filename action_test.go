@@ -0,0 +1,245 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testSite struct {
+	BaseObject
+	Name string `json:"name"`
+}
+
+func TestCallAction(t *testing.T) {
+	api := testAPI()
+	api["/api/v1/Building/Room(site)"] = testDescribe{"Action", Describe{Name: "site", Path: "/api/v1/Building/Room(site)", ReturnType: FieldParamater{Type: "Model", URI: "/api/v1/Building/Site"}}}
+	api["/api/v1/Building/Room(sites)"] = testDescribe{"Action", Describe{Name: "sites", Path: "/api/v1/Building/Room(sites)", Static: true, ReturnType: FieldParamater{Type: "Model", URI: "/api/v1/Building/Site", IsArray: true}}}
+	api["/api/v1/Building/Site(when)"] = testDescribe{"Action", Describe{Name: "when", Path: "/api/v1/Building/Site(when)", Static: true, ReturnType: FieldParamater{Type: "DateTime"}}}
+	api["/api/v1/Building/Site(bad)"] = testDescribe{"Action", Describe{Name: "bad", Path: "/api/v1/Building/Site(bad)", Static: true, ReturnType: FieldParamater{Type: "Integer"}}}
+
+	var calls []string
+	var gotArgs map[string]interface{}
+	server := newTestAPIServer(api, func(rw http.ResponseWriter, req *http.Request) {
+		calls = append(calls, req.Method+" "+req.URL.Path)
+		if req.Method == "GET" {
+			json.NewEncoder(rw).Encode(map[string]interface{}{"name": "site " + req.URL.Path})
+			return
+		}
+
+		gotArgs = map[string]interface{}{}
+		json.NewDecoder(req.Body).Decode(&gotArgs)
+		switch req.URL.Path {
+		case "/api/v1/Building/Room:1:(move)":
+			json.NewEncoder(rw).Encode(true)
+		case "/api/v1/Building/Site(summary)":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"rooms": 4})
+		case "/api/v1/Building/Room:1:(site)":
+			json.NewEncoder(rw).Encode("/api/v1/Building/Site:4:")
+		case "/api/v1/Building/Room(sites)":
+			json.NewEncoder(rw).Encode([]string{"/api/v1/Building/Site:4:", "/api/v1/Building/Site:5:"})
+		case "/api/v1/Building/Site(when)":
+			json.NewEncoder(rw).Encode("2024-01-02T03:04:05.123456")
+		case "/api/v1/Building/Site(bad)":
+			json.NewEncoder(rw).Encode("twelve")
+		}
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+
+	var moved bool
+	for _, test := range []struct {
+		uri  string
+		args map[string]interface{}
+	}{
+		{"/api/v1/Building/Room(move)", map[string]interface{}{"site": "/api/v1/Building/Site:1:"}},            // not static, needs ids
+		{"/api/v1/Building/Site:1:(summary)", nil},                                                             // static, no ids
+		{"/api/v1/Building/Room:1:2:(move)", map[string]interface{}{"site": "/api/v1/Building/Site:1:"}},       // multi
+		{"/api/v1/Building/Room", nil},                                                                         // not an action
+		{"/api/v1/Building/Room:1:(move)", map[string]interface{}{}},                                           // missing required
+		{"/api/v1/Building/Room:1:(move)", map[string]interface{}{"site": "/api/v1/Building/Room:1:"}},         // wrong model
+		{"/api/v1/Building/Room:1:(move)", map[string]interface{}{"site": "/api/v1/Building/Site:1:", "x": 1}}, // unknown
+	} {
+		if err := c.CallAction(ctx, test.uri, test.args, &moved); err == nil {
+			t.Errorf("error missing for '%s' '%v'", test.uri, test.args)
+			t.FailNow()
+		}
+	}
+	if len(calls) != 0 {
+		t.Errorf("Invalid calls were sent '%v'", calls)
+		t.FailNow()
+	}
+
+	err = c.CallAction(ctx, "/api/v1/Building/Room:1:(move)", map[string]interface{}{"site": "/api/v1/Building/Site:2:", "note": "moving"}, &moved)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !moved || !reflect.DeepEqual(gotArgs, map[string]interface{}{"site": "/api/v1/Building/Site:2:", "note": "moving"}) {
+		t.Errorf("Wrong result '%v' or args '%v'", moved, gotArgs)
+		t.FailNow()
+	}
+
+	summary := map[string]int{}
+	if err = c.CallAction(ctx, "/api/v1/Building/Site(summary)", nil, &summary); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if summary["rooms"] != 4 {
+		t.Errorf("Wrong summary '%v'", summary)
+		t.FailNow()
+	}
+
+	var siteURI string
+	if err = c.CallAction(ctx, "/api/v1/Building/Room:1:(site)", nil, &siteURI); err != nil || siteURI != "/api/v1/Building/Site:4:" {
+		t.Errorf("Expected URI got '%s' '%v'", siteURI, err)
+		t.FailNow()
+	}
+
+	calls = nil
+	var site Object
+	if err = c.CallAction(ctx, "/api/v1/Building/Room:1:(site)", nil, &site); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	mo, ok := site.(*MappedObject)
+	if !ok || mo.GetURI() != "/api/v1/Building/Site:4:" || mo.Data["name"] != "site /api/v1/Building/Site:4:" {
+		t.Errorf("Wrong object '%+v'", site)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(calls, []string{"CALL /api/v1/Building/Room:1:(site)", "GET /api/v1/Building/Site:4:"}) {
+		t.Errorf("Wrong calls '%v'", calls)
+		t.FailNow()
+	}
+
+	typed := &testSite{}
+	if err = c.CallAction(ctx, "/api/v1/Building/Room:1:(site)", nil, typed); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if typed.GetURI() != "/api/v1/Building/Site:4:" || typed.Name != "site /api/v1/Building/Site:4:" {
+		t.Errorf("Wrong object '%+v'", typed)
+		t.FailNow()
+	}
+
+	var sites []Object
+	if err = c.CallAction(ctx, "/api/v1/Building/Room(sites)", nil, &sites); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if len(sites) != 2 || sites[1].GetURI() != "/api/v1/Building/Site:5:" {
+		t.Errorf("Wrong objects '%+v'", sites)
+		t.FailNow()
+	}
+
+	var when time.Time
+	if err = c.CallAction(ctx, "/api/v1/Building/Site(when)", nil, &when); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !when.Equal(time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)) {
+		t.Errorf("Wrong time '%s'", when)
+		t.FailNow()
+	}
+
+	var number int
+	err = c.CallAction(ctx, "/api/v1/Building/Site(bad)", nil, &number)
+	var invalid *InvalidValue
+	if !errors.As(err, &invalid) {
+		t.Errorf("Expected InvalidValue got '%v'", err)
+		t.FailNow()
+	}
+
+	if err = c.CallAction(ctx, "/api/v1/Building/Site(summary)", nil, nil); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+}
+
+func TestCallMultiAs(t *testing.T) {
+	api := testAPI()
+	api["/api/v1/Building/Room(count)"] = testDescribe{"Action", Describe{Name: "count", Path: "/api/v1/Building/Room(count)", ReturnType: FieldParamater{Type: "Integer"}}}
+	api["/api/v1/Building/Room(site)"] = testDescribe{"Action", Describe{Name: "site", Path: "/api/v1/Building/Room(site)", ReturnType: FieldParamater{Type: "Model", URI: "/api/v1/Building/Site"}}}
+
+	var multiHeader string
+	server := newTestAPIServer(api, func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == "GET" {
+			json.NewEncoder(rw).Encode(map[string]interface{}{"name": "site"})
+			return
+		}
+
+		multiHeader = req.Header.Get("Multi-Object")
+		rw.Header().Set("Multi-Object", "True")
+		switch req.URL.Path {
+		case "/api/v1/Building/Room:1:2:3:(count)":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"/api/v1/Building/Room:1:": 4, "/api/v1/Building/Room:2:": 0, "/api/v1/Building/Room:3:": "many"})
+		case "/api/v1/Building/Room:1:2:(site)":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"/api/v1/Building/Room:1:": "/api/v1/Building/Site:4:", "/api/v1/Building/Room:2:": "/api/v1/Building/Site:5:"})
+		}
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+
+	if _, err = CallMultiAs[int](ctx, c, "/api/v1/Building/Room(count)", nil); err == nil {
+		t.Errorf("error missing for model URI")
+		t.FailNow()
+	}
+	if _, err = CallMultiAs[int](ctx, c, "/api/v1/Building/Room:1:2:3:(count)", map[string]interface{}{"x": 1}); err == nil {
+		t.Errorf("error missing for unknown paramater")
+		t.FailNow()
+	}
+
+	counts, err := CallMultiAs[int](ctx, c, "/api/v1/Building/Room:1:2:3:(count)", nil)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if multiHeader != "True" {
+		t.Errorf("Multi-Object header not sent")
+		t.FailNow()
+	}
+	if len(counts) != 3 || counts["/api/v1/Building/Room:1:"].Value != 4 || counts["/api/v1/Building/Room:1:"].Err != nil || counts["/api/v1/Building/Room:2:"].Value != 0 || counts["/api/v1/Building/Room:2:"].Err != nil {
+		t.Errorf("Wrong results '%+v'", counts)
+		t.FailNow()
+	}
+	if counts["/api/v1/Building/Room:3:"].Err == nil {
+		t.Errorf("error missing for bad result")
+		t.FailNow()
+	}
+
+	sites, err := CallMultiAs[string](ctx, c, "/api/v1/Building/Room:1:2:(site)", nil)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if sites["/api/v1/Building/Room:2:"].Value != "/api/v1/Building/Site:5:" {
+		t.Errorf("Wrong results '%+v'", sites)
+		t.FailNow()
+	}
+
+	objects, err := CallMultiAs[Object](ctx, c, "/api/v1/Building/Room:1:2:(site)", nil)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if object := objects["/api/v1/Building/Room:1:"].Value; object == nil || object.GetURI() != "/api/v1/Building/Site:4:" {
+		t.Errorf("Wrong results '%+v'", objects)
+		t.FailNow()
+	}
+}
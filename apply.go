@@ -0,0 +1,474 @@
+package cinp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Actions of a PlanItem
+const (
+	PlanCreate = "create"
+	PlanUpdate = "update"
+	PlanDelete = "delete"
+	PlanNone   = "none"
+)
+
+// ManifestObject is the values a object should have, the object is the one with Id in Model.  IdField is the
+// field of the values the server makes the id from, it is required to create the object.
+type ManifestObject struct {
+	Model   string                 `json:"model"`
+	Id      string                 `json:"id"`
+	IdField string                 `json:"id_field,omitempty"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// UnmarshalJSON takes the id as a string or a number, ie YAML's id: 1
+func (o *ManifestObject) UnmarshalJSON(data []byte) error {
+	type plain ManifestObject
+	var value struct {
+		plain
+		Id interface{} `json:"id"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	*o = ManifestObject(value.plain)
+	if value.Id == nil {
+		return nil
+	}
+
+	id, ok := idString(value.Id)
+	if !ok {
+		return fmt.Errorf("id '%v' is not a string or whole number", value.Id)
+	}
+	o.Id = id
+
+	return nil
+}
+
+// Manifest is the objects that should exist, they are created and updated in order
+type Manifest struct {
+	Objects []ManifestObject `json:"objects"`
+}
+
+// LoadManifest loads a JSON manifest
+func LoadManifest(reader io.Reader) (*Manifest, error) {
+	result := &Manifest{}
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(result); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest: %w", err)
+	}
+
+	return result, nil
+}
+
+// FieldChange is the live and wanted value of a field
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// PlanItem is what needs to be done to one object
+type PlanItem struct {
+	Action  string                 `json:"action"`
+	URI     string                 `json:"uri"`
+	Values  map[string]interface{} `json:"values,omitempty"`  // to create with
+	Changes map[string]FieldChange `json:"changes,omitempty"` // to update
+}
+
+// Plan is the changes to make the server match a Manifest, see CInP.Plan
+type Plan struct {
+	Items []PlanItem `json:"items"`
+}
+
+// Count returns the number of items with the action
+func (p *Plan) Count(action string) int {
+	result := 0
+	for _, item := range p.Items {
+		if item.Action == action {
+			result++
+		}
+	}
+
+	return result
+}
+
+// HasChanges is true if there is anything to apply
+func (p *Plan) HasChanges() bool {
+	return len(p.Items) > p.Count(PlanNone)
+}
+
+func planValue(value interface{}) string {
+	buff, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(buff)
+}
+
+// Write writes the plan for people to read, objects that need no changes are left out
+func (p *Plan) Write(w io.Writer) error {
+	for _, item := range p.Items {
+		var err error
+		switch item.Action {
+		case PlanCreate:
+			_, err = fmt.Fprintf(w, "+ create %s\n", item.URI)
+			for _, name := range sortedKeys(item.Values, nil) {
+				if err == nil {
+					_, err = fmt.Fprintf(w, "    %s: %s\n", name, planValue(item.Values[name]))
+				}
+			}
+
+		case PlanUpdate:
+			_, err = fmt.Fprintf(w, "~ update %s\n", item.URI)
+			for _, name := range sortedKeys(item.Changes, nil) {
+				if err == nil {
+					change := item.Changes[name]
+					_, err = fmt.Fprintf(w, "    %s: %s -> %s\n", name, planValue(change.Old), planValue(change.New))
+				}
+			}
+
+		case PlanDelete:
+			_, err = fmt.Fprintf(w, "- delete %s\n", item.URI)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete, %d unchanged.\n", p.Count(PlanCreate), p.Count(PlanUpdate), p.Count(PlanDelete), p.Count(PlanNone))
+
+	return err
+}
+
+// sameJSON compares values the way they would be compared after a trip through JSON, see sameValue
+func sameJSON(a interface{}, b interface{}) bool {
+	normalize := func(value interface{}) (interface{}, bool) {
+		buff, err := json.Marshal(value)
+		if err != nil {
+			return nil, false
+		}
+		var result interface{}
+		if err := decodeJSON(buff, &result); err != nil {
+			return nil, false
+		}
+		return result, true
+	}
+
+	aValue, aOk := normalize(a)
+	bValue, bOk := normalize(b)
+	if !aOk || !bOk {
+		return reflect.DeepEqual(a, b)
+	}
+
+	return sameNormalized(aValue, bValue)
+}
+
+func sameNormalized(a interface{}, b interface{}) bool {
+	switch aValue := a.(type) {
+	case map[string]interface{}:
+		bValue, ok := b.(map[string]interface{})
+		if !ok || len(aValue) != len(bValue) {
+			return false
+		}
+		for key, item := range aValue {
+			other, ok := bValue[key]
+			if !ok || !sameNormalized(item, other) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		bValue, ok := b.([]interface{})
+		if !ok || len(aValue) != len(bValue) {
+			return false
+		}
+		for i := range aValue {
+			if !sameNormalized(aValue[i], bValue[i]) {
+				return false
+			}
+		}
+		return true
+
+	case json.Number: // integers are compared as integers so large ids keep their precision
+		if bValue, ok := b.(json.Number); ok {
+			aInt, aErr := aValue.Int64()
+			bInt, bErr := bValue.Int64()
+			if aErr == nil && bErr == nil {
+				return aInt == bInt
+			}
+		}
+	}
+
+	return sameValue(a, b)
+}
+
+// sameFieldValue compares the live and wanted value of a field, DateTimes are compared as times
+func sameFieldValue(field FieldParamater, live interface{}, wanted interface{}) bool {
+	if field.Type == "DateTime" && !field.IsArray {
+		liveString, liveOk := live.(string)
+		wantedString, wantedOk := wanted.(string)
+		if liveOk && wantedOk {
+			liveTime, liveErr := parseDateTime(liveString)
+			wantedTime, wantedErr := parseDateTime(wantedString)
+			if liveErr == nil && wantedErr == nil {
+				return liveTime.Equal(wantedTime)
+			}
+		}
+	}
+
+	return sameJSON(live, wanted)
+}
+
+// modelFields returns the fields of the model by name, and checks that uri is a model
+func (cinp *CInP) modelFields(ctx context.Context, uri string) (map[string]FieldParamater, []FieldParamater, error) {
+	describe, describeType, err := cinp.Describe(ctx, uri)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if describeType != "Model" {
+		return nil, nil, fmt.Errorf("expected '%s' to be a 'Model' got '%s'", uri, describeType)
+	}
+
+	result := make(map[string]FieldParamater, len(describe.Fields))
+	for _, field := range describe.Fields {
+		result[field.Name] = field
+	}
+
+	return result, describe.Fields, nil
+}
+
+// idString is the id value as it would be in a URI, false if value can not be a id
+func idString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return v.String(), true
+		}
+	}
+
+	if number, ok := toFloat(value); ok && number == math.Trunc(number) {
+		return fmt.Sprintf("%.0f", number), true
+	}
+
+	return "", false
+}
+
+// planObject plans one object of the manifest
+func (cinp *CInP) planObject(ctx context.Context, object ManifestObject, fields map[string]FieldParamater, fieldList []FieldParamater, uri string) (PlanItem, error) {
+	for name, value := range object.Values {
+		if err := checkNamedValue(fieldList, name, value); err != nil {
+			return PlanItem{}, fmt.Errorf("'%s': %w", uri, err)
+		}
+		if fields[name].Mode == "RO" {
+			return PlanItem{}, fmt.Errorf("'%s': %w", uri, &InvalidValue{Name: name, Reason: "read only"})
+		}
+	}
+
+	if object.IdField != "" {
+		value, ok := object.Values[object.IdField]
+		if !ok {
+			return PlanItem{}, fmt.Errorf("'%s': %w", uri, &InvalidValue{Name: object.IdField, Reason: "id field is not in the values"})
+		}
+		if id, ok := idString(value); !ok || id != object.Id {
+			return PlanItem{}, fmt.Errorf("'%s': %w", uri, &InvalidValue{Name: object.IdField, Reason: fmt.Sprintf("'%v' does not match the id '%s'", value, object.Id)})
+		}
+	}
+
+	live := &MappedObject{}
+	if err := cinp.GetInto(ctx, uri, live); err != nil {
+		if errors.As(err, new(*NotFound)) {
+			if object.IdField == "" { // with out it, the server may give the object some other id every apply
+				return PlanItem{}, fmt.Errorf("'%s': id_field is required to create the object", uri)
+			}
+			return PlanItem{Action: PlanCreate, URI: uri, Values: object.Values}, nil
+		}
+		return PlanItem{}, err
+	}
+
+	changes := map[string]FieldChange{}
+	for name, value := range object.Values {
+		liveValue := live.Data[name]
+		if sameFieldValue(fields[name], liveValue, value) {
+			continue
+		}
+		if fields[name].Mode == "RC" {
+			return PlanItem{}, fmt.Errorf("'%s': %w", uri, &InvalidValue{Name: name, Reason: "can only be set when created"})
+		}
+		changes[name] = FieldChange{Old: liveValue, New: value}
+	}
+
+	if len(changes) == 0 {
+		return PlanItem{Action: PlanNone, URI: uri}, nil
+	}
+
+	return PlanItem{Action: PlanUpdate, URI: uri, Changes: changes}, nil
+}
+
+// Plan compares the objects in the manifest to the server, objects that do not exist are created and objects
+// with different values are updated, only the fields in the manifest are compared.  If prune is true, the objects
+// of the models in the manifest that are not in the manifest are deleted.  The values are checked against the
+// model's fields, read only fields can not be set and create only fields can not be changed.
+func (cinp *CInP) Plan(ctx context.Context, manifest *Manifest, prune bool) (*Plan, error) {
+	type model struct {
+		fields    map[string]FieldParamater
+		fieldList []FieldParamater
+		wanted    map[string]bool // object URIs
+	}
+
+	result := &Plan{Items: []PlanItem{}}
+	modelList := []string{}
+	models := map[string]*model{}
+	seen := map[string]bool{}
+
+	for i, object := range manifest.Objects {
+		u := cinp.uriFor(object.Model)
+		ns, modelName, action, ids, _, err := u.Split(object.Model)
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", i, err)
+		}
+		if modelName == "" || action != "" || ids != nil {
+			return nil, fmt.Errorf("object %d: '%s' is not a model URI", i, object.Model)
+		}
+		if object.Id == "" {
+			return nil, fmt.Errorf("object %d: id is required", i)
+		}
+
+		uri, err := u.Build(ns, modelName, "", []string{object.Id})
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", i, err)
+		}
+		if seen[uri] {
+			return nil, fmt.Errorf("object %d: '%s' is in the manifest more than once", i, uri)
+		}
+		seen[uri] = true
+
+		item, ok := models[object.Model]
+		if !ok {
+			fields, fieldList, err := cinp.modelFields(ctx, object.Model)
+			if err != nil {
+				return nil, err
+			}
+			item = &model{fields: fields, fieldList: fieldList, wanted: map[string]bool{}}
+			models[object.Model] = item
+			modelList = append(modelList, object.Model)
+		}
+		item.wanted[uri] = true
+
+		planItem, err := cinp.planObject(ctx, object, item.fields, item.fieldList, uri)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, planItem)
+	}
+
+	if !prune {
+		return result, nil
+	}
+
+	for i := len(modelList) - 1; i >= 0; i-- { // delete in the reverse order the models where first used
+		uri := modelList[i]
+		position := 0
+		total := 1
+		for position < total {
+			uriList, listPosition, count, listTotal, err := cinp.List(ctx, uri, "", nil, position, 50)
+			if err != nil {
+				return nil, err
+			}
+			for _, objectURI := range uriList {
+				if !models[uri].wanted[objectURI] {
+					result.Items = append(result.Items, PlanItem{Action: PlanDelete, URI: objectURI})
+				}
+			}
+			if count == 0 {
+				break
+			}
+			position = listPosition + count
+			total = listTotal
+		}
+	}
+
+	return result, nil
+}
+
+// applyCreate creates the object of a PlanCreate item in the item's model
+func (cinp *CInP) applyCreate(ctx context.Context, item PlanItem) error {
+	parsed, err := cinp.uriFor(item.URI).Parse(item.URI)
+	if err != nil {
+		return err
+	}
+
+	model, ok := parsed.ParentModel()
+	if !ok {
+		return fmt.Errorf("'%s' is not a object URI", item.URI)
+	}
+
+	object, err := cinp.Create(ctx, model.String(), &MappedObject{Data: item.Values})
+	if err != nil {
+		return err
+	}
+
+	if (*object).GetURI() != item.URI {
+		return fmt.Errorf("created as '%s'", (*object).GetURI())
+	}
+
+	return nil
+}
+
+// ApplyProgress is called after each item of a plan is applied, err is the error applying it
+type ApplyProgress func(item PlanItem, err error)
+
+// Apply applies the plan in order, stopping at the first error.  Created objects must get the URI in the plan,
+// Plan checks this with the manifest's IdField.  progress may be nil.
+func (cinp *CInP) Apply(ctx context.Context, plan *Plan, progress ApplyProgress) error {
+	for _, item := range plan.Items {
+		var err error
+		switch item.Action {
+		case PlanCreate:
+			err = cinp.applyCreate(ctx, item)
+
+		case PlanUpdate:
+			values := make(map[string]interface{}, len(item.Changes))
+			for name, change := range item.Changes {
+				values[name] = change.New
+			}
+			object := &MappedObject{Data: values}
+			object.SetURI(item.URI)
+			_, err = cinp.Update(ctx, object)
+
+		case PlanDelete:
+			err = cinp.DeleteURI(ctx, item.URI)
+
+		case PlanNone:
+			continue
+		}
+
+		if err != nil {
+			err = fmt.Errorf("%s '%s': %w", item.Action, item.URI, err)
+		}
+		if progress != nil {
+			progress(item, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
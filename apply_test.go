@@ -0,0 +1,255 @@
+package cinp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testSiteStore is a server for the Sites, by name
+func testSiteStore(sites map[string]map[string]interface{}) http.HandlerFunc {
+	var mutex sync.Mutex
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		values := map[string]interface{}{}
+		json.NewDecoder(req.Body).Decode(&values)
+		name := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/api/v1/Building/Site:"), ":")
+
+		switch req.Method {
+		case "LIST":
+			uriList := []string{}
+			for name := range sites {
+				uriList = append(uriList, "/api/v1/Building/Site:"+name+":")
+			}
+			sort.Strings(uriList)
+			rw.Header().Set("Position", "0")
+			rw.Header().Set("Count", "50")
+			rw.Header().Set("Total", "0")
+			json.NewEncoder(rw).Encode(uriList)
+			return
+
+		case "CREATE":
+			name = values["name"].(string)
+			sites[name] = values
+			rw.Header().Set("Object-Id", "/api/v1/Building/Site:"+name+":")
+			rw.WriteHeader(201)
+			json.NewEncoder(rw).Encode(values)
+			return
+		}
+
+		site, ok := sites[name]
+		if !ok {
+			rw.WriteHeader(404)
+			return
+		}
+
+		switch req.Method {
+		case "GET":
+			json.NewEncoder(rw).Encode(site)
+
+		case "UPDATE":
+			for key, value := range values {
+				site[key] = value
+			}
+			json.NewEncoder(rw).Encode(site)
+
+		case "DELETE":
+			delete(sites, name)
+		}
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	sites := map[string]map[string]interface{}{
+		"a": {"name": "a", "description": "same", "created": "2024-01-02T03:04:05+00:00"},
+		"b": {"name": "b", "description": "old"},
+		"c": {"name": "c", "description": "extra"},
+	}
+	server := newTestAPIServer(testAPI(), testSiteStore(sites))
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+
+	manifest, err := LoadManifest(strings.NewReader(`{"objects": [
+  {"model": "/api/v1/Building/Site", "id": "a", "values": {"name": "a", "description": "same"}},
+  {"model": "/api/v1/Building/Site", "id": "b", "values": {"name": "b", "description": "new"}},
+  {"model": "/api/v1/Building/Site", "id": "d", "id_field": "name", "values": {"name": "d", "description": "added"}}
+]}`))
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	plan, err := c.Plan(ctx, manifest, false)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if len(plan.Items) != 3 || plan.Count(PlanDelete) != 0 {
+		t.Errorf("Wrong plan '%+v'", plan.Items)
+		t.FailNow()
+	}
+
+	plan, err = c.Plan(ctx, manifest, true)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	buff := &bytes.Buffer{}
+	if err := plan.Write(buff); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	expected := `~ update /api/v1/Building/Site:b:
+    description: "old" -> "new"
++ create /api/v1/Building/Site:d:
+    description: "added"
+    name: "d"
+- delete /api/v1/Building/Site:c:
+Plan: 1 to create, 1 to update, 1 to delete, 1 unchanged.
+`
+	if buff.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, buff.String())
+		t.FailNow()
+	}
+
+	applied := []string{}
+	err = c.Apply(ctx, plan, func(item PlanItem, err error) {
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+		}
+		applied = append(applied, item.Action+" "+item.URI)
+	})
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(applied, []string{"update /api/v1/Building/Site:b:", "create /api/v1/Building/Site:d:", "delete /api/v1/Building/Site:c:"}) {
+		t.Errorf("Wrong applied '%v'", applied)
+		t.FailNow()
+	}
+
+	if len(sites) != 3 || sites["b"]["description"] != "new" || sites["d"]["description"] != "added" {
+		t.Errorf("Wrong sites '%v'", sites)
+		t.FailNow()
+	}
+
+	plan, err = c.Plan(ctx, manifest, true)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if plan.HasChanges() {
+		t.Errorf("Unexpected changes '%+v'", plan.Items)
+		t.FailNow()
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	sites := map[string]map[string]interface{}{
+		"a": {"name": "a", "description": "same", "created": "2024-01-02T03:04:05+00:00"},
+	}
+	server := newTestAPIServer(testAPI(), testSiteStore(sites))
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	tests := map[string][]ManifestObject{
+		"is not a model URI":            {{Model: "/api/v1/Building/", Id: "a"}},
+		"id is required":                {{Model: "/api/v1/Building/Site", Values: map[string]interface{}{}}},
+		"more than once":                {{Model: "/api/v1/Building/Site", Id: "a"}, {Model: "/api/v1/Building/Site", Id: "a"}},
+		"read only":                     {{Model: "/api/v1/Building/Site", Id: "a", Values: map[string]interface{}{"created": "2024-01-02T03:04:05"}}},
+		"can only be set when created":  {{Model: "/api/v1/Building/Site", Id: "a", Values: map[string]interface{}{"name": "z"}}},
+		"unknown":                       {{Model: "/api/v1/Building/Site", Id: "a", Values: map[string]interface{}{"bogus": 1}}},
+		"expected a String":             {{Model: "/api/v1/Building/Site", Id: "a", Values: map[string]interface{}{"description": 1}}},
+		"does not match the id":         {{Model: "/api/v1/Building/Site", Id: "z", IdField: "name", Values: map[string]interface{}{"name": "y"}}},
+		"id field is not in the values": {{Model: "/api/v1/Building/Site", Id: "z", IdField: "name", Values: map[string]interface{}{}}},
+		"id_field is required":          {{Model: "/api/v1/Building/Site", Id: "z", Values: map[string]interface{}{"name": "z"}}},
+	}
+	for expected, objectList := range tests {
+		_, err := c.Plan(context.TODO(), &Manifest{Objects: objectList}, false)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error with '%s' got '%v'", expected, err)
+			t.FailNow()
+		}
+	}
+
+	if _, err := LoadManifest(strings.NewReader(`{"objects": [{"model": "/api/v1/Building/Site", "ids": "a"}]}`)); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	numbered, err := LoadManifest(strings.NewReader(`{"objects": [{"model": "/api/v1/Building/Room", "id": 12, "values": {}}]}`))
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if numbered.Objects[0].Id != "12" {
+		t.Errorf("Expected id '12' got '%s'", numbered.Objects[0].Id)
+		t.FailNow()
+	}
+
+	for _, id := range []string{"1.5", "true", "[1]"} {
+		if _, err := LoadManifest(strings.NewReader(`{"objects": [{"model": "/api/v1/Building/Room", "id": ` + id + `}]}`)); err == nil {
+			t.Errorf("error missing for id '%s'", id)
+			t.FailNow()
+		}
+	}
+
+	for _, uri := range []string{"bad", "/api/v1/Building/"} {
+		plan := &Plan{Items: []PlanItem{{Action: PlanCreate, URI: uri, Values: map[string]interface{}{"name": "z"}}}}
+		if err := c.Apply(context.TODO(), plan, nil); err == nil {
+			t.Errorf("error missing for '%s'", uri)
+			t.FailNow()
+		}
+	}
+	if len(sites) != 1 {
+		t.Errorf("Wrong sites '%v'", sites)
+		t.FailNow()
+	}
+}
+
+func TestSameFieldValue(t *testing.T) {
+	tests := []struct {
+		field    FieldParamater
+		live     interface{}
+		wanted   interface{}
+		expected bool
+	}{
+		{FieldParamater{Type: "Integer"}, json.Number("4"), 4, true},
+		{FieldParamater{Type: "Integer"}, json.Number("9007199254740993"), json.Number("9007199254740992"), false},
+		{FieldParamater{Type: "Float"}, json.Number("1.0"), 1, true},
+		{FieldParamater{Type: "DateTime"}, "2024-01-02T03:04:05+00:00", "2024-01-02T03:04:05Z", true},
+		{FieldParamater{Type: "DateTime"}, "2024-01-02T03:04:05+00:00", "2024-01-02T03:04:06Z", false},
+		{FieldParamater{Type: "Map"}, map[string]interface{}{"a": json.Number("1")}, map[string]interface{}{"a": 1.0}, true},
+		{FieldParamater{Type: "Map"}, map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1, "b": 2}, false},
+		{FieldParamater{Type: "String", IsArray: true}, []interface{}{"a", "b"}, []string{"a", "b"}, true},
+		{FieldParamater{Type: "String", IsArray: true}, []interface{}{"a", "b"}, []string{"b", "a"}, false},
+		{FieldParamater{Type: "Model"}, nil, "/api/v1/Building/Site:1:", false},
+	}
+	for _, test := range tests {
+		if result := sameFieldValue(test.field, test.live, test.wanted); result != test.expected {
+			t.Errorf("Expected '%v' for '%v' and '%v'", test.expected, test.live, test.wanted)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package cinp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotAttempted is the error for items of a bulk operation that where not tried, because a earlier item
+// failed or the context was done
+var ErrNotAttempted = errors.New("not attempted")
+
+// DeleteResult is the outcome of deleting one object with DeleteMulti
+type DeleteResult struct {
+	URI string
+	Err error
+}
+
+type uriBatch struct {
+	uri     string
+	objects []string // the URI of each object in uri
+	indexes [][]int  // into the results, of each object
+}
+
+// uriBatches groups the uris of single objects by model, into batches of at most the model's namespace
+// multi-uri-max ids, a uri that is in uriList more than once is only in the batch once
+func (cinp *CInP) uriBatches(ctx context.Context, uriList []string) ([]uriBatch, error) {
+	type group struct {
+		uri     *URI
+		ns      []string
+		model   string
+		ids     []string
+		indexes [][]int        // of each id
+		seen    map[string]int // id to offset in ids
+	}
+
+	groupList := []*group{}
+	groups := map[string]*group{}
+	for i, uri := range uriList {
+		u := cinp.uriFor(uri)
+		ns, model, action, ids, _, err := u.Split(uri)
+		if err != nil {
+			return nil, err
+		}
+		if model == "" || action != "" || len(ids) != 1 || ids[0] == "" {
+			return nil, fmt.Errorf("'%s' is not a URI of one object", uri)
+		}
+
+		key, err := u.Build(ns, model, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		item, ok := groups[key]
+		if !ok {
+			item = &group{uri: u, ns: ns, model: model, seen: map[string]int{}}
+			groups[key] = item
+			groupList = append(groupList, item)
+		}
+		if offset, ok := item.seen[ids[0]]; ok {
+			item.indexes[offset] = append(item.indexes[offset], i)
+			continue
+		}
+		item.seen[ids[0]] = len(item.ids)
+		item.ids = append(item.ids, ids[0])
+		item.indexes = append(item.indexes, []int{i})
+	}
+
+	result := []uriBatch{}
+	for _, item := range groupList {
+		namespace, err := item.uri.Build(item.ns, "", "", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		describe, _, err := cinp.Describe(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		size := describe.MultiURIMax
+		if size < 1 { // not set, don't risk it
+			size = 1
+		}
+
+		for start := 0; start < len(item.ids); start += size {
+			end := min(start+size, len(item.ids))
+			uri, err := item.uri.Build(item.ns, item.model, "", item.ids[start:end])
+			if err != nil {
+				return nil, err
+			}
+			objects := make([]string, 0, end-start)
+			for _, id := range item.ids[start:end] {
+				object, err := item.uri.Build(item.ns, item.model, "", []string{id})
+				if err != nil {
+					return nil, err
+				}
+				objects = append(objects, object)
+			}
+			result = append(result, uriBatch{uri: uri, objects: objects, indexes: item.indexes[start:end]})
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteMulti deletes the objects, uriList is URIs of single objects which can be of different models.  The
+// objects are deleted in batches of up to the namespace's multi-uri-max, with at most parallelism batches at a
+// time.  The results are in the same order as uriList, the objects of a failed batch are deleted again one at
+// a time so each gets it's own error.  If stopOnError is true, no more batches are started after a failure,
+// their objects get ErrNotAttempted.  The
+// error is only for problems before deleting, ie invalid URIs.
+func (cinp *CInP) DeleteMulti(ctx context.Context, uriList []string, parallelism int, stopOnError bool) ([]DeleteResult, error) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	batchList, err := cinp.uriBatches(ctx, uriList)
+	if err != nil {
+		return nil, err
+	}
+
+	cinp.log.Info("DELETE(bulk)", "count", len(uriList), "batches", len(batchList), "parallelism", parallelism)
+
+	result := make([]DeleteResult, len(uriList))
+	for i, uri := range uriList {
+		result[i] = DeleteResult{URI: uri, Err: ErrNotAttempted}
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	failed := false
+	sem := make(chan struct{}, parallelism)
+
+	for _, batch := range batchList {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		mutex.Lock()
+		stop := ctx.Err() != nil || (stopOnError && failed)
+		mutex.Unlock()
+		if stop {
+			break
+		}
+
+		wg.Add(1)
+		go func(batch uriBatch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			errList := make([]error, len(batch.objects))
+			if err := cinp.DeleteURI(ctx, batch.uri); err != nil {
+				if len(batch.objects) == 1 {
+					errList[0] = err
+				} else {
+					cinp.log.Debug("DELETE(bulk) batch failed, deleting one at a time", "uri", batch.uri, "error", err)
+					for i, uri := range batch.objects {
+						errList[i] = cinp.DeleteURI(ctx, uri)
+					}
+				}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			for i, err := range errList {
+				if err != nil {
+					failed = true
+				}
+				for _, index := range batch.indexes[i] {
+					result[index].Err = err
+				}
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// DeleteMultiIds deletes the objects of the model at uri with the ids, see DeleteMulti
+func (cinp *CInP) DeleteMultiIds(ctx context.Context, uri string, ids []string, parallelism int, stopOnError bool) ([]DeleteResult, error) {
+	uriList := make([]string, len(ids))
+	for i, id := range ids {
+		var err error
+		if uriList[i], err = cinp.uriFor(uri).UpdateIDs(uri, []string{id}); err != nil {
+			return nil, err
+		}
+	}
+
+	return cinp.DeleteMulti(ctx, uriList, parallelism, stopOnError)
+}
+
+// BulkResult is the outcome of one item of BulkCreate or BulkUpdate
+type BulkResult struct {
+	Object Object // the created/updated object, nil if Err is set
+	Err    error
+}
+
+// BulkProgress is called as each item of a bulk operation finishes, done is the number finished so far.  Calls
+// are not concurrent.
+type BulkProgress func(done int, total int)
+
+// runBulk calls fn for 0 to count-1 with parallelism workers, the results are in index order.  Once ctx is done
+// no more items are started, the ones already started finish (with what ever error ctx causes), items that
+// are not started get ErrNotAttempted.
+func runBulk(ctx context.Context, count int, parallelism int, progress BulkProgress, fn func(ctx context.Context, index int) (Object, error)) []BulkResult {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	result := make([]BulkResult, count)
+	for i := range result {
+		result[i].Err = ErrNotAttempted
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	done := 0
+	queue := make(chan int)
+
+	for i := 0; i < min(parallelism, count); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				object, err := fn(ctx, index)
+
+				mutex.Lock()
+				result[index] = BulkResult{Object: object, Err: err}
+				done++
+				if progress != nil {
+					progress(done, count)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < count; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return result
+}
+
+// BulkCreate creates the objects in the model at uri with Create, making at most parallelism requests at a
+// time.  The results are in the same order as objectList, a failure does not stop the other items.  Once ctx is
+// done no more items are started, BulkCreate waits for the ones in flight and the rest get ErrNotAttempted.
+// The client has no retry or rate limit settings, each item is one Create with out retries, parallelism is the
+// only limit on the request rate.  To retry, call BulkCreate again with the items that failed.
+func (cinp *CInP) BulkCreate(ctx context.Context, uri string, objectList []Object, parallelism int, progress BulkProgress) []BulkResult {
+	cinp.log.Info("CREATE(bulk)", "uri", uri, "count", len(objectList), "parallelism", parallelism)
+
+	return runBulk(ctx, len(objectList), parallelism, progress, func(ctx context.Context, index int) (Object, error) {
+		object, err := cinp.Create(ctx, uri, objectList[index])
+		if err != nil {
+			return nil, err
+		}
+		return *object, nil
+	})
+}
+
+// BulkUpdate updates the objects with Update, see BulkCreate
+func (cinp *CInP) BulkUpdate(ctx context.Context, objectList []Object, parallelism int, progress BulkProgress) []BulkResult {
+	cinp.log.Info("UPDATE(bulk)", "count", len(objectList), "parallelism", parallelism)
+
+	return runBulk(ctx, len(objectList), parallelism, progress, func(ctx context.Context, index int) (Object, error) {
+		object, err := cinp.Update(ctx, objectList[index])
+		if err != nil {
+			return nil, err
+		}
+		return *object, nil
+	})
+}
@@ -0,0 +1,231 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeleteMulti(t *testing.T) {
+	var mutex sync.Mutex
+	var deleted []string
+	server := newTestAPIServer(testAPI(), func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != "DELETE" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mutex.Lock()
+		deleted = append(deleted, req.URL.Path)
+		mutex.Unlock()
+		if strings.Contains(req.URL.Path, ":4:") { // Room 4 does not exist
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+
+	for _, uriList := range [][]string{{"/api/v1/Building/Room"}, {"/api/v1/Building/Room:1:2:"}, {"/api/v1/Building/Room:1:(move)"}, {"/api/v1/Building/Room::"}, {"/bad"}} {
+		if _, err = c.DeleteMulti(ctx, uriList, 2, false); err == nil {
+			t.Errorf("error missing for '%s'", uriList)
+			t.FailNow()
+		}
+	}
+	if len(deleted) != 0 {
+		t.Errorf("Deletes sent for invalid URIs '%v'", deleted)
+		t.FailNow()
+	}
+
+	uriList := []string{"/api/v1/Building/Room:1:", "/api/v1/Building/Site:1:", "/api/v1/Building/Room:2:", "/api/v1/Building/Room:3:", "/api/v1/Building/Room:4:", "/api/v1/Building/Room:5:"}
+	result, err := c.DeleteMulti(ctx, uriList, 3, false)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	sort.Strings(deleted)
+	// the failed batch is deleted again one at a time
+	if !reflect.DeepEqual(deleted, []string{"/api/v1/Building/Room:1:2:", "/api/v1/Building/Room:3:", "/api/v1/Building/Room:3:4:", "/api/v1/Building/Room:4:", "/api/v1/Building/Room:5:", "/api/v1/Building/Site:1:"}) {
+		t.Errorf("Wrong batches '%v'", deleted)
+		t.FailNow()
+	}
+
+	for i, item := range result {
+		if item.URI != uriList[i] {
+			t.Errorf("Results out of order '%v'", result)
+			t.FailNow()
+		}
+		failed := item.URI == "/api/v1/Building/Room:4:"
+		var notFound *NotFound
+		if failed != errors.As(item.Err, &notFound) || (!failed && item.Err != nil) {
+			t.Errorf("Wrong error for '%s': '%v'", item.URI, item.Err)
+			t.FailNow()
+		}
+	}
+
+	// duplicates are only deleted once, and each gets the result
+	deleted = nil
+	uriList = []string{"/api/v1/Building/Room:1:", "/api/v1/Building/Room:2:", "/api/v1/Building/Room:1:"}
+	result, err = c.DeleteMulti(ctx, uriList, 1, false)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(deleted, []string{"/api/v1/Building/Room:1:2:"}) {
+		t.Errorf("Wrong batches '%v'", deleted)
+		t.FailNow()
+	}
+	for i, item := range result {
+		if item.URI != uriList[i] || item.Err != nil {
+			t.Errorf("Wrong results '%v'", result)
+			t.FailNow()
+		}
+	}
+
+	deleted = nil
+	result, err = c.DeleteMultiIds(ctx, "/api/v1/Building/Room", []string{"3", "4", "5", "6", "7"}, 1, true)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(deleted, []string{"/api/v1/Building/Room:3:4:", "/api/v1/Building/Room:3:", "/api/v1/Building/Room:4:"}) {
+		t.Errorf("Expected to stop after the first batch got '%v'", deleted)
+		t.FailNow()
+	}
+	if result[0].URI != "/api/v1/Building/Room:3:" || result[0].Err != nil || result[1].Err == nil || result[4].URI != "/api/v1/Building/Room:7:" || result[4].Err != ErrNotAttempted {
+		t.Errorf("Wrong results '%v'", result)
+		t.FailNow()
+	}
+
+	if _, err = c.DeleteMultiIds(ctx, "/api/v1/Building/", []string{"1"}, 1, true); err == nil {
+		t.Errorf("error missing for namespace")
+		t.FailNow()
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	deleted = nil
+	result, err = c.DeleteMultiIds(cancelCtx, "/api/v1/Building/Room", []string{"1"}, 1, false)
+	if err == nil && (len(deleted) != 0 || result[0].Err != ErrNotAttempted) {
+		t.Errorf("Expected nothing deleted got '%v' '%v'", deleted, result)
+		t.FailNow()
+	}
+}
+
+func TestBulkCreateUpdate(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight := 0
+	maxInFlight := 0
+	server := newTestAPIServer(testAPI(), func(rw http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mutex.Unlock()
+		defer func() {
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+		}()
+		time.Sleep(5 * time.Millisecond)
+
+		values := map[string]interface{}{}
+		json.NewDecoder(req.Body).Decode(&values)
+		if values["name"] == "bad" {
+			rw.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(rw).Encode(map[string]interface{}{"message": "bad name"})
+			return
+		}
+
+		if req.Method == "CREATE" {
+			rw.Header().Set("Object-Id", fmt.Sprintf("/api/v1/Building/Site:%s:", values["name"]))
+			rw.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(rw).Encode(values)
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+
+	objectList := []Object{}
+	for i := 0; i < 20; i++ {
+		name := strconv.Itoa(i)
+		if i == 7 {
+			name = "bad"
+		}
+		objectList = append(objectList, &testSite{Name: name})
+	}
+
+	progressList := []int{}
+	result := c.BulkCreate(ctx, "/api/v1/Building/Site", objectList, 4, func(done int, total int) {
+		if total != 20 {
+			t.Errorf("Wrong total %d", total)
+		}
+		progressList = append(progressList, done)
+	})
+
+	if len(result) != 20 || len(progressList) != 20 || progressList[19] != 20 {
+		t.Errorf("Wrong result count %d or progress '%v'", len(result), progressList)
+		t.FailNow()
+	}
+	if maxInFlight > 4 || maxInFlight < 2 {
+		t.Errorf("Expected up to 4 requests at a time got %d", maxInFlight)
+		t.FailNow()
+	}
+	for i, item := range result {
+		if i == 7 {
+			var invalid *InvalidRequest
+			if !errors.As(item.Err, &invalid) || item.Object != nil {
+				t.Errorf("Expected InvalidRequest got '%v'", item.Err)
+				t.FailNow()
+			}
+			continue
+		}
+		if item.Err != nil || item.Object.GetURI() != fmt.Sprintf("/api/v1/Building/Site:%d:", i) {
+			t.Errorf("Wrong result %d '%+v'", i, item)
+			t.FailNow()
+		}
+	}
+
+	updateList := []Object{result[0].Object, result[1].Object}
+	result = c.BulkUpdate(ctx, updateList, 0, nil)
+	if len(result) != 2 || result[0].Err != nil || result[1].Object.(*testSite).Name != "1" {
+		t.Errorf("Wrong update result '%+v'", result)
+		t.FailNow()
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	result = c.BulkCreate(cancelCtx, "/api/v1/Building/Site", objectList, 2, func(done int, total int) {
+		if done == 3 {
+			cancel()
+		}
+	})
+	attempted := 0
+	for _, item := range result {
+		if item.Err != ErrNotAttempted {
+			attempted++
+		}
+	}
+	if attempted < 3 || attempted > 5 || result[19].Err != ErrNotAttempted {
+		t.Errorf("Expected the bulk to stop after the cancel, %d attempted", attempted)
+		t.FailNow()
+	}
+}
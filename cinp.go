@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,12 +38,24 @@ type CInPClient interface {
 
 // CInP client struct
 type CInP struct {
-	host         string
-	uri          *URI
-	proxy        string
-	headers      map[string]string
-	typeRegistry map[string]reflect.Type
-	log          *slog.Logger
+	host    string
+	uri     *URI     // of the rootPath passed to NewCInP
+	mounts  []*mount // longest root path first
+	proxy   string
+	headers map[string]string
+	log     *slog.Logger
+	// types
+	strictTypes bool
+	// compression
+	requestEncoding string
+	acceptEncodings []string
+	// describe cache, nil when not enabled
+	describeCache *describeCache
+	// api version negotiation
+	apiVersionMutex       sync.Mutex
+	apiVersionConstraint  string
+	apiVersionComparators []apiVersionComparator
+	apiVersions           map[string]string // namespace -> api-version
 }
 
 const httpTrue = "True"
@@ -100,9 +113,10 @@ func NewCInP(log *slog.Logger, host string, rootPath string, proxy string) (*CIn
 	cinp := CInP{}
 	cinp.host = host
 	cinp.uri = uri
+	cinp.mounts = []*mount{{uri: uri, typeRegistry: newTypeRegistry()}}
 	cinp.proxy = proxy
-	cinp.typeRegistry = map[string]reflect.Type{}
 	cinp.headers = map[string]string{}
+	cinp.apiVersions = map[string]string{}
 	cinp.log = log
 
 	cinp.log.Info("New client", "host", host)
@@ -122,7 +136,7 @@ func (cinp *CInP) ClearHeader(name string) {
 	delete(cinp.headers, name)
 }
 
-// GetURI get the uri
+// GetURI get the uri of the rootPath passed to NewCInP, see MountURI for the other mounts
 func (cinp *CInP) GetURI() *URI {
 	return cinp.uri
 }
@@ -130,6 +144,12 @@ func (cinp *CInP) GetURI() *URI {
 func (cinp *CInP) request(ctx context.Context, verb string, uri string, dataIn interface{}, dataOut interface{}, headers map[string]string) (int, map[string]string, error) {
 	var body []byte
 
+	if verb != "DESCRIBE" && cinp.apiVersionConstrained() {
+		if _, err := cinp.CheckAPIVersion(ctx, uri); err != nil {
+			return 0, nil, err
+		}
+	}
+
 	cinp.log.Debug("request", "extra headers", headers)
 
 	if dataIn != nil {
@@ -148,6 +168,14 @@ func (cinp *CInP) request(ctx context.Context, verb string, uri string, dataIn i
 		cinp.log.Debug("request", slog.Any("data", body))
 	}
 
+	if cinp.requestEncoding != "" && len(body) > 0 { // compress after logging so the log has the plain data
+		var err error
+		body, err = compressBody(cinp.requestEncoding, body)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
 	client := http.Client{
 		Timeout: time.Second * 30,
 	}
@@ -170,16 +198,21 @@ func (cinp *CInP) request(ctx context.Context, verb string, uri string, dataIn i
 	req.Header.Set("Accept-Charset", "utf-8")
 	req.Header.Set("CInP-Version", "1.0")
 	req.Header.Set("Content-Type", "application/json;charset=utf-8")
+	if cinp.requestEncoding != "" && len(body) > 0 {
+		req.Header.Set("Content-Encoding", cinp.requestEncoding)
+	}
+	if len(cinp.acceptEncodings) > 0 { // setting this disables the http client's transparent gzip, decompressBody takes care of it
+		req.Header.Set("Accept-Encoding", strings.Join(cinp.acceptEncodings, ", "))
+	}
 
 	res, err := client.Do(req)
 	if err != nil {
 		return 0, nil, err
 	}
 
-	cinp.log.Debug("result", slog.Int("code", res.StatusCode))
+	defer res.Body.Close()
 
-	logReader := NewReaderForLogging(500)
-	bodyReader := io.TeeReader(res.Body, logReader)
+	cinp.log.Debug("result", slog.Int("code", res.StatusCode))
 
 	switch res.StatusCode {
 	case 401:
@@ -193,6 +226,15 @@ func (cinp *CInP) request(ctx context.Context, verb string, uri string, dataIn i
 		return 0, nil, fmt.Errorf("HTTP Code '%d' unhandled", res.StatusCode)
 	}
 
+	resBody, err := decompressBody(res.Header.Get("Content-Encoding"), res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resBody.Close()
+
+	logReader := NewReaderForLogging(500)
+	bodyReader := io.TeeReader(resBody, logReader) // tee after decompressing so the log has the decoded data
+
 	if res.StatusCode == 400 || res.StatusCode == 500 {
 		// So some 400 and 500 responses might not be JSON encoded, other than saving the
 		// body to the side in the case of Decode error, not sure what else to do.  When a
@@ -280,8 +322,39 @@ type Describe struct {
 	Paramaters []FieldParamater `json:"paramaters"`
 }
 
-// Describe the URI
+// Describe the URI, if the describe cache is enabled the result may come from the cache, treat the result as read only
 func (cinp *CInP) Describe(ctx context.Context, uri string) (*Describe, string, error) {
+	cache := cinp.describeCache
+	if cache == nil {
+		return cinp.describe(ctx, uri)
+	}
+
+	if err := cinp.checkDescribeCache(ctx, cache); err != nil {
+		return nil, "", err
+	}
+
+	if result, describeType, ok := cache.get(uri); ok {
+		cinp.log.Debug("DESCRIBE (cached)", "uri", uri)
+		return result, describeType, nil
+	}
+
+	result, describeType, err := cinp.describe(ctx, uri)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if uri == cinp.uri.rootPath {
+		if cache.setRoot(uri, result, describeType) {
+			cinp.log.Info("API Version changed, describe cache invalidated", "version", result.APIVersion)
+		}
+	} else {
+		cache.set(uri, result, describeType)
+	}
+
+	return result, describeType, nil
+}
+
+func (cinp *CInP) describe(ctx context.Context, uri string) (*Describe, string, error) {
 	result := &Describe{}
 	cinp.log.Info("DESCRIBE", "uri", uri)
 
@@ -329,38 +402,39 @@ func (mo *MappedObject) AsMap(isCreate bool) *map[string]interface{} {
 	return &mo.Data
 }
 
-// MappedObjectType is the type used for the MappedObject which is used if a uri is not found in the type table
+// MappedObjectType is the type used for the MappedObject which is used if a uri is not found in the type table,
+// unless strict types are on (see SetStrictTypes)
 var MappedObjectType = reflect.TypeOf((*MappedObject)(nil)).Elem()
 
-// RegisterType registeres the type to use for a url
+// RegisterType registeres the type to use for a model's url, see RegisterTypePattern for registering more than
+// one model
 func (cinp *CInP) RegisterType(uri string, objectType reflect.Type) {
-	object := reflect.New(objectType).Interface()
-	_, ok := object.(Object)
-	if !ok {
-		panic(fmt.Sprintf("%v does not implement Object", objectType))
+	if err := checkObjectType(objectType); err != nil {
+		panic(err.Error())
 	}
 
-	cinp.typeRegistry[uri] = objectType
+	cinp.mountFor(uri).typeRegistry.exact[uri] = objectType
 }
 
-func (cinp *CInP) objectType(uri string) reflect.Type {
-	offset := strings.IndexByte(uri, ':')
-	if offset != -1 {
-		uri = uri[:offset]
+// decodeObject decodes raw into a new object of the type registered for the uri, and sets the object's URI
+func (cinp *CInP) decodeObject(uri string, raw json.RawMessage) (Object, error) {
+	result, err := cinp.newObject(uri)
+	if err != nil {
+		return nil, err
 	}
 
-	objectType, ok := cinp.typeRegistry[uri]
-	if !ok {
-		return MappedObjectType
+	var target interface{} = result
+	if mo, ok := result.(*MappedObject); ok {
+		target = (*Map)(&mo.Data)
 	}
 
-	return objectType
-}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return nil, fmt.Errorf("unable to parse '%s': %w", uri, err)
+	}
 
-func (cinp *CInP) newObject(uri string) Object {
-	objectType := cinp.objectType(uri)
+	result.SetURI(uri)
 
-	return reflect.New(objectType).Interface().(Object)
+	return result, nil
 }
 
 // List objects
@@ -446,7 +520,7 @@ func (cinp *CInP) ListObjects(ctx context.Context, uri string, objectType reflec
 				// not sure what to do with the error
 				break
 			}
-			ids, err := cinp.uri.ExtractIds(itemList)
+			ids, err := cinp.uriFor(uri).ExtractIds(itemList)
 			if err != nil {
 				// not sure what to do with the error
 				break
@@ -455,7 +529,12 @@ func (cinp *CInP) ListObjects(ctx context.Context, uri string, objectType reflec
 			//       My golang fu is not good enough to figure out how to make, return, pass, and iterate over a map made with refelect.Type
 			//       perhaps there is another way to get it to work, for now do this very ugly get one at a time mess
 			for _, id := range ids {
-				object, err := cinp.Get(ctx, uri+":"+id+":")
+				objectURI, err := cinp.uriFor(uri).UpdateIDs(uri, []string{id})
+				if err != nil {
+					// not sure what to do with the error
+					break
+				}
+				object, err := cinp.Get(ctx, objectURI)
 				if err != nil {
 					// not sure what to do with the error
 					break
@@ -480,57 +559,80 @@ func (cinp *CInP) ListObjects(ctx context.Context, uri string, objectType reflec
 
 // Get gets an object from the URI, if the Multi-Object header is set on the result, this will error out
 func (cinp *CInP) Get(ctx context.Context, uri string) (*Object, error) {
+	result, err := cinp.newObject(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cinp.GetInto(ctx, uri, result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetInto gets the object from the URI into object, for when the type of the object is known and not registered
+func (cinp *CInP) GetInto(ctx context.Context, uri string, object Object) error {
 	var err error
 	var code int
 	var headers map[string]string
 
 	cinp.log.Info("GET", "uri", uri)
 
-	result := cinp.newObject(uri)
-	if mo, ok := result.(*MappedObject); ok {
-		code, headers, err = cinp.request(ctx, "GET", uri, nil, &mo.Data, nil)
+	if mo, ok := object.(*MappedObject); ok {
+		code, headers, err = cinp.request(ctx, "GET", uri, nil, (*Map)(&mo.Data), nil)
 	} else {
-		code, headers, err = cinp.request(ctx, "GET", uri, nil, result, nil)
+		code, headers, err = cinp.request(ctx, "GET", uri, nil, object, nil)
 	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if code != 200 {
-		return nil, fmt.Errorf("unexpected HTTP code '%d'", code)
+		return fmt.Errorf("unexpected HTTP code '%d'", code)
 	}
 
 	if headers["Multi-Object"] == httpTrue {
-		return nil, fmt.Errorf("detected multi object")
+		return fmt.Errorf("detected multi object")
 	}
 
-	result.SetURI(uri)
+	object.SetURI(uri)
 
-	return &result, nil
+	return nil
 }
 
-// GetMulti get objects from the URI, forces the Muti-Object header
-// func (cinp *CInP) GetMulti(uri string) (*map[string]Object, error) {
-// 	headers := map[string]string{"Multi-Object": "True"}
-// 	mapType := reflect.MapOf(reflect.TypeOf(""), cinp.objectType(uri))
-// 	result := reflect.MakeMap(mapType).Interface()
-// 	code, headers, err := cinp.request("GET", uri, nil, result, headers)
-// 	fmt.Printf("3  %+v\n", result)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-//
-// 	if code != 200 {
-// 		return nil, fmt.Errorf("Unexpected HTTP code '%d'", code)
-// 	}
-//
-// 	if headers["Multi-Object"] != httpTrue {
-// 		return nil, fmt.Errorf("None Multi result detected")
-// 	}
-//
-// 	//return result.(map[string]Object), nil
-// 	return &map[string]Object{}, nil
-// }
+// GetMulti get objects from the URI, forces the Muti-Object header.  The objects are the type registered for
+// the URI (see RegisterType), by URI
+func (cinp *CInP) GetMulti(ctx context.Context, uri string) (map[string]Object, error) {
+	headers := map[string]string{"Multi-Object": "True"}
+	rawMap := map[string]json.RawMessage{}
+
+	cinp.log.Info("GET(multi)", "uri", uri)
+
+	code, headers, err := cinp.request(ctx, "GET", uri, nil, &rawMap, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if code != 200 {
+		return nil, fmt.Errorf("unexpected HTTP code '%d'", code)
+	}
+
+	if headers["Multi-Object"] != httpTrue {
+		return nil, fmt.Errorf("no multi result detected")
+	}
+
+	result := make(map[string]Object, len(rawMap))
+	for objectURI, raw := range rawMap {
+		object, err := cinp.decodeObject(objectURI, raw)
+		if err != nil {
+			return nil, err
+		}
+		result[objectURI] = object
+	}
+
+	return result, nil
+}
 
 // Create an object with the values
 func (cinp *CInP) Create(ctx context.Context, uri string, object Object) (*Object, error) {
@@ -545,7 +647,7 @@ func (cinp *CInP) Create(ctx context.Context, uri string, object Object) (*Objec
 		return nil, fmt.Errorf("unexpected HTTP code '%d'", code)
 	}
 
-	_, _, _, ids, _, err := cinp.uri.Split(headers["Object-Id"])
+	_, _, _, ids, _, err := cinp.uriFor(headers["Object-Id"]).Split(headers["Object-Id"])
 	if err != nil {
 		return nil, err
 	}
@@ -580,13 +682,15 @@ func (cinp *CInP) Update(ctx context.Context, object Object) (*Object, error) {
 	return &object, nil
 }
 
-// UpdateMulti update the objects with the values, forces the Muti-Object header
+// UpdateMulti update the objects with the values, forces the Muti-Object header.  The updated objects are
+// added to result by URI, as the type registered for the URI (see RegisterType), result may be nil
 func (cinp *CInP) UpdateMulti(ctx context.Context, uri string, values *map[string]interface{}, result *map[string]Object) error {
 	headers := map[string]string{"Multi-Object": "True"}
+	rawMap := map[string]json.RawMessage{}
 
 	cinp.log.Info("UPDATE(multi)", "uri", uri)
 
-	code, headers, err := cinp.request(ctx, "UPDATE", uri, values, result, headers)
+	code, headers, err := cinp.request(ctx, "UPDATE", uri, values, &rawMap, headers)
 	if err != nil {
 		return err
 	}
@@ -599,6 +703,22 @@ func (cinp *CInP) UpdateMulti(ctx context.Context, uri string, values *map[strin
 		return fmt.Errorf("no multi result detected")
 	}
 
+	if result == nil {
+		return nil
+	}
+
+	if *result == nil {
+		*result = map[string]Object{}
+	}
+
+	for objectURI, raw := range rawMap {
+		object, err := cinp.decodeObject(objectURI, raw)
+		if err != nil {
+			return err
+		}
+		(*result)[objectURI] = object
+	}
+
 	return nil
 }
 
@@ -689,7 +809,7 @@ func NewURI(rootPath string) (*URI, error) {
 		return nil, errors.New("root path must start and end with '/'")
 	}
 
-	r, err := regexp.Compile("^(" + rootPath + ")(([a-zA-Z0-9\\-_.!~*]+/)*)([a-zA-Z0-9\\-_.!~*]+)?(:([a-zA-Z0-9\\-_.!~*\\']*:)*)?(\\([a-zA-Z0-9\\-_.!~*]+\\))?$")
+	r, err := regexp.Compile("^(" + regexp.QuoteMeta(rootPath) + ")(([a-zA-Z0-9\\-_.!~*]+/)*)([a-zA-Z0-9\\-_.!~*]+)?(:((?:[a-zA-Z0-9\\-_.!~*\\']|%[0-9A-F]{2})*:)+)?(\\([a-zA-Z0-9\\-_.!~*]+\\))?$")
 	if err != nil {
 		return nil, err
 	}
@@ -701,7 +821,86 @@ func NewURI(rootPath string) (*URI, error) {
 	return u, nil
 }
 
-// Split the uri into it's parts
+// isNameChar is true for the characters allowed in namespace, model and action names
+func isNameChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.IndexByte("-_.!~*", c) != -1
+}
+
+// isIdChar is true for the characters allowed in ids with out escaping
+func isIdChar(c byte) bool {
+	return isNameChar(c) || c == '\''
+}
+
+// EscapeId escapes the characters of id that are not allowed in a URI (including ':'), as %XX
+func EscapeId(id string) string {
+	var builder strings.Builder
+	for i := 0; i < len(id); i++ {
+		if isIdChar(id[i]) {
+			builder.WriteByte(id[i])
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", id[i])
+		}
+	}
+
+	return builder.String()
+}
+
+// UnescapeId reverses EscapeId, only the escapes EscapeId makes are allowed, so every id has one URI form
+func UnescapeId(id string) (string, error) {
+	if strings.IndexByte(id, '%') == -1 {
+		return id, nil
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(id); i++ {
+		if id[i] != '%' {
+			builder.WriteByte(id[i])
+			continue
+		}
+
+		if i+2 >= len(id) {
+			return "", fmt.Errorf("invalid escape in id '%s'", id)
+		}
+		value, err := strconv.ParseUint(id[i+1:i+3], 16, 8)
+		if err != nil || isIdChar(byte(value)) || strings.ToUpper(id[i+1:i+3]) != id[i+1:i+3] {
+			return "", fmt.Errorf("invalid escape in id '%s'", id)
+		}
+		builder.WriteByte(byte(value))
+		i += 2
+	}
+
+	return builder.String(), nil
+}
+
+// checkName checks that a namespace, model or action name can be in a URI
+func checkName(kind string, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s name is empty", kind)
+	}
+
+	for i := 0; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return fmt.Errorf("invalid character '%c' in %s name '%s'", name[i], kind, name)
+		}
+	}
+
+	return nil
+}
+
+// splitIds splits and unescapes the ids group of the regex, ie ":a:b:"
+func splitIds(group string) ([]string, error) {
+	result := strings.Split(group[1:len(group)-1], ":")
+	for i, id := range result {
+		var err error
+		if result[i], err = UnescapeId(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Split the uri into it's parts, the ids are unescaped
 func (u *URI) Split(uri string) ([]string, string, string, []string, bool, error) {
 	groups := u.uriRegex.FindStringSubmatch(uri)
 	if len(groups) < 8 {
@@ -713,6 +912,10 @@ func (u *URI) Split(uri string) ([]string, string, string, []string, bool, error
 		return nil, "", "", nil, false, errors.New("URI does not start in the rootPath")
 	}
 
+	if groups[4] == "" && (groups[5] != "" || groups[7] != "") {
+		return nil, "", "", nil, false, fmt.Errorf("unable to parse URI '%s', ids and actions need a model", uri)
+	}
+
 	var namespaceList []string
 	if groups[2] != "" {
 		namespaceList = strings.Split(strings.Trim(groups[2], "/"), "/")
@@ -721,7 +924,10 @@ func (u *URI) Split(uri string) ([]string, string, string, []string, bool, error
 	var ids []string
 	var multi bool
 	if groups[5] != "" {
-		ids = strings.Split(strings.Trim(groups[5], ":"), ":")
+		var err error
+		if ids, err = splitIds(groups[5]); err != nil {
+			return nil, "", "", nil, false, err
+		}
 		multi = len(ids) > 1
 	} else {
 		ids = nil // ids = [] is an empty list of ids, where nil means the list is not even present
@@ -736,32 +942,50 @@ func (u *URI) Split(uri string) ([]string, string, string, []string, bool, error
 	return namespaceList, groups[4], action, ids, multi, nil
 }
 
-// Build constructs a URI from the paramaters, NOTE: if model is "", ids and action are skiped
-func (u *URI) Build(namespace []string, model string, action string, ids []string) string {
+// Build constructs a URI from the paramaters, the ids are escaped, see EscapeId.  Names that can not be in a URI
+// are an error.  NOTE: if model is "", ids and action are skiped
+func (u *URI) Build(namespace []string, model string, action string, ids []string) (string, error) {
 	result := u.rootPath
 
+	for _, name := range namespace {
+		if err := checkName("namespace", name); err != nil {
+			return "", err
+		}
+	}
+
 	if len(namespace) > 0 {
 		result += strings.Join(namespace, "/") + "/"
 	}
 
 	if model == "" {
-		return result
+		return result, nil
+	}
+
+	if err := checkName("model", model); err != nil {
+		return "", err
 	}
 
 	result += model
 
 	if len(ids) > 0 {
-		result += ":" + strings.Join(ids, ":") + ":"
+		escaped := make([]string, len(ids))
+		for i, id := range ids {
+			escaped[i] = EscapeId(id)
+		}
+		result += ":" + strings.Join(escaped, ":") + ":"
 	}
 
 	if action != "" {
+		if err := checkName("action", action); err != nil {
+			return "", err
+		}
 		result += "(" + action + ")"
 	}
 
-	return result
+	return result, nil
 }
 
-// ExtractIds extract the id(s) from a list of URI
+// ExtractIds extract the id(s) from a list of URI, the ids are unescaped
 func (u *URI) ExtractIds(uriList []string) ([]string, error) {
 	result := make([]string, 0)
 	for _, v := range uriList {
@@ -770,21 +994,25 @@ func (u *URI) ExtractIds(uriList []string) ([]string, error) {
 			return nil, fmt.Errorf("unable to parse URI '%s'", v)
 		}
 		if groups[5] != "" {
-			result = append(result, strings.Split(strings.Trim(groups[5], ":"), ":")...)
+			ids, err := splitIds(groups[5])
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, ids...)
 		}
 	}
 
 	return result, nil
 }
 
-// UpdateIDs update/set the id(s) in the uri
+// UpdateIDs update/set the id(s) in the uri, the ids are escaped
 func (u *URI) UpdateIDs(uri string, ids []string) (string, error) {
 	ns, model, action, _, _, err := u.Split(uri)
 	if err != nil {
 		return "", err
 	}
 
-	return u.Build(ns, model, action, ids), nil
+	return u.Build(ns, model, action, ids)
 }
 
 // helper functions and types
@@ -3,11 +3,13 @@ package cinp
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -62,13 +64,21 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [], '', '', nil, false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r := u.Build(ns, model, action, idList)
+	r, err := u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/" {
 		t.Errorf("Expected '/api/v1/' got '%s'", r)
 		t.FailNow()
 	}
 	ns = nil
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/" {
 		t.Errorf("Expected '/api/v1/' got '%s'", r)
 		t.FailNow()
@@ -83,7 +93,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], '', '', nil, false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/" {
 		t.Errorf("Expected '/api/v1/ns/' got '%s'", r)
 		t.FailNow()
@@ -98,13 +112,21 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], 'model', '', nil, false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model" {
 		t.Errorf("Expected '/api/v1/ns/model' got '%s'", r)
 		t.FailNow()
 	}
 	idList = []string{}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model" {
 		t.Errorf("Expected '/api/v1/ns/model' got '%s'", r)
 		t.FailNow()
@@ -119,7 +141,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns ns2], '', '', nil, false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/ns2/" {
 		t.Errorf("Expected '/api/v1/ns/ns2/' got '%s'", r)
 		t.FailNow()
@@ -134,7 +160,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns ns2], 'model', '', nil, false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/ns2/model" {
 		t.Errorf("Expected '/api/v1/ns/ns2/model' got '%s'", r)
 		t.FailNow()
@@ -149,7 +179,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], 'model', '', [], false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model::" {
 		t.Errorf("Expected '/api/v1/ns/model::' got '%s'", r)
 		t.FailNow()
@@ -164,7 +198,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], 'model', '', [ghj], false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model:ghj:" {
 		t.Errorf("Expected '/api/v1/ns/model:ghj:' got '%s'", r)
 		t.FailNow()
@@ -179,7 +217,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], 'model', '', [ghj dsf sfe], true got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model:ghj:dsf:sfe:" {
 		t.Errorf("Expected '/api/v1/ns/model:ghj:dsf:sfe:' got '%s'", r)
 		t.FailNow()
@@ -194,7 +236,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], 'model', 'action', nil, false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model(action)" {
 		t.Errorf("Expected '/api/v1/ns/model(action)' got '%s'", r)
 		t.FailNow()
@@ -209,7 +255,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], 'model', 'action', [sdf], false got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model:sdf:(action)" {
 		t.Errorf("Expected '/api/v1/ns/model:sdf:(action)' got '%s'", r)
 		t.FailNow()
@@ -224,7 +274,11 @@ func TestURISplitBuild(t *testing.T) {
 		t.Errorf("Expected [ns], 'model', 'action', [sdf eed], true got %s, '%s', '%s', %s, %t", ns, model, action, idList, multi)
 		t.FailNow()
 	}
-	r = u.Build(ns, model, action, idList)
+	r, err = u.Build(ns, model, action, idList)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
 	if r != "/api/v1/ns/model:sdf:eed:(action)" {
 		t.Errorf("Expected '/api/v1/ns/model:sdf:eed:(action)' got '%s'", r)
 		t.FailNow()
@@ -520,3 +574,363 @@ func TestLogging(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+type testRoom struct {
+	BaseObject
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+func TestUpdateMulti(t *testing.T) {
+	var gotValues map[string]interface{}
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotValues = map[string]interface{}{}
+		json.NewDecoder(req.Body).Decode(&gotValues)
+		rw.Header().Set("Multi-Object", "True")
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"/api/v1/Building/Room:1:": map[string]interface{}{"name": "one", "size": 5},
+			"/api/v1/Building/Room:2:": map[string]interface{}{"name": "two", "size": 5},
+			"/api/v1/Building/Site:3:": map[string]interface{}{"name": "site"},
+		})
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	c.RegisterType("/api/v1/Building/Room", reflect.TypeOf((*testRoom)(nil)).Elem())
+
+	var result map[string]Object
+	err = c.UpdateMulti(context.TODO(), "/api/v1/Building/Room:1:2:", &map[string]interface{}{"size": 5}, &result)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(gotValues, map[string]interface{}{"size": 5.0}) {
+		t.Errorf("Wrong values sent '%v'", gotValues)
+		t.FailNow()
+	}
+	if len(result) != 3 {
+		t.Errorf("Expected 3 results got '%v'", result)
+		t.FailNow()
+	}
+
+	room, ok := result["/api/v1/Building/Room:2:"].(*testRoom)
+	if !ok || room.Name != "two" || room.Size != 5 || room.GetURI() != "/api/v1/Building/Room:2:" {
+		t.Errorf("Wrong room '%+v'", result["/api/v1/Building/Room:2:"])
+		t.FailNow()
+	}
+
+	site, ok := result["/api/v1/Building/Site:3:"].(*MappedObject)
+	if !ok || site.Data["name"] != "site" || site.GetURI() != "/api/v1/Building/Site:3:" {
+		t.Errorf("Wrong site '%+v'", result["/api/v1/Building/Site:3:"])
+		t.FailNow()
+	}
+
+	if err = c.UpdateMulti(context.TODO(), "/api/v1/Building/Room:1:2:", &map[string]interface{}{"size": 5}, nil); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+}
+
+func TestURIEscape(t *testing.T) {
+	u, err := NewURI("/api/v1/")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	tests := map[string]string{
+		"abc":     "abc",
+		"a:b":     "a%3Ab",
+		"a/b":     "a%2Fb",
+		"a%b":     "a%25b",
+		"it's":    "it's",
+		"a b(c)":  "a%20b%28c%29",
+		"é":       "%C3%A9",
+		"":        "",
+		"-_.!~*'": "-_.!~*'",
+	}
+	for id, escaped := range tests {
+		if EscapeId(id) != escaped {
+			t.Errorf("Expected '%s' got '%s' for '%s'", escaped, EscapeId(id), id)
+			t.FailNow()
+		}
+		result, err := UnescapeId(escaped)
+		if err != nil || result != id {
+			t.Errorf("Expected '%s' got '%s' '%v'", id, result, err)
+			t.FailNow()
+		}
+	}
+
+	for _, v := range []string{"%", "%3", "%3a", "%41", "%zz", "a%2"} {
+		if _, err := UnescapeId(v); err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+	}
+
+	r, err := u.Build([]string{"ns"}, "model", "action", []string{"a:b", "c/d"})
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if r != "/api/v1/ns/model:a%3Ab:c%2Fd:(action)" {
+		t.Errorf("Expected '/api/v1/ns/model:a%%3Ab:c%%2Fd:(action)' got '%s'", r)
+		t.FailNow()
+	}
+
+	_, _, _, idList, multi, err := u.Split(r)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(idList, []string{"a:b", "c/d"}) || !multi {
+		t.Errorf("Expected [a:b c/d] got %s", idList)
+		t.FailNow()
+	}
+
+	idList, err = u.ExtractIds([]string{r, "/api/v1/ns/model:e%25f:"})
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(idList, []string{"a:b", "c/d", "e%f"}) {
+		t.Errorf("Expected [a:b c/d e%%f] got %s", idList)
+		t.FailNow()
+	}
+
+	r, err = u.UpdateIDs("/api/v1/ns/model:1:(action)", []string{"x y"})
+	if err != nil || r != "/api/v1/ns/model:x%20y:(action)" {
+		t.Errorf("Expected '/api/v1/ns/model:x%%20y:(action)' got '%s' '%v'", r, err)
+		t.FailNow()
+	}
+
+	for _, v := range []string{"/api/v1/ns/model:%3a:", "/api/v1/ns/model:%41:", "/api/v1/ns/model:%:", "/api/v1/ns/model:a b:"} {
+		if _, _, _, _, _, err := u.Split(v); err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+		if _, err := u.ExtractIds([]string{v}); err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+	}
+
+	badList := []struct {
+		namespace []string
+		model     string
+		action    string
+	}{
+		{[]string{"n/s"}, "model", ""},
+		{[]string{""}, "model", ""},
+		{[]string{"ns"}, "mo:del", ""},
+		{[]string{"ns"}, "model", "act(ion)"},
+		{[]string{"ns"}, "model", "act ion"},
+		{[]string{"ns's"}, "", ""},
+	}
+	for _, v := range badList {
+		if _, err := u.Build(v.namespace, v.model, v.action, []string{"1"}); err == nil {
+			t.Errorf("error missing for '%v'", v)
+			t.FailNow()
+		}
+	}
+}
+
+func FuzzURIBuildSplit(f *testing.F) {
+	f.Add("ns", "model", "action", "abc", "")
+	f.Add("ns", "model", "", "a:b", "c/d")
+	f.Add("", "model", "", "", "%41")
+	f.Add("ns", "model", "act", "é", "it's")
+
+	u, err := NewURI("/api/v1/")
+	if err != nil {
+		f.Errorf("Unexpected error '%s'", err)
+		f.FailNow()
+	}
+
+	f.Fuzz(func(t *testing.T, namespace string, model string, action string, id1 string, id2 string) {
+		var ns []string
+		if namespace != "" {
+			ns = []string{namespace}
+		}
+
+		r, err := u.Build(ns, model, action, []string{id1, id2})
+		if err != nil {
+			return // names that can't be in a URI
+		}
+
+		ns2, model2, action2, idList, multi, err := u.Split(r)
+		if err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, r)
+			t.FailNow()
+		}
+
+		if model == "" { // ids and action are skipped
+			if !reflect.DeepEqual(ns2, ns) || model2 != "" || action2 != "" || idList != nil {
+				t.Errorf("Wrong split of '%s'", r)
+			}
+			return
+		}
+
+		if !reflect.DeepEqual(ns2, ns) || model2 != model || action2 != action || !reflect.DeepEqual(idList, []string{id1, id2}) || !multi {
+			t.Errorf("Round trip failed '%v' '%s' '%s' '%q' -> '%s' -> '%v' '%s' '%s' '%q'", ns, model, action, []string{id1, id2}, r, ns2, model2, action2, idList)
+		}
+	})
+}
+
+// uriSeeds are the URIs from the hand-picked tests above
+var uriSeeds = []string{
+	"/apdsf", "/api/v1/", "/api/v1/ns/", "/api/v1/ns/model", "/api/v1/ns/ns2/", "/api/v1/ns/ns2/model", "/api/v1/ns/model::",
+	"/api/v1/ns/model:ghj:", "/api/v1/ns/model:ghj:dsf:sfe:", "/api/v1/ns/model(action)", "/api/v1/ns/model:sdf:(action)",
+	"/api/v1/ns/model:sdf:eed:(action)", "api/v1", "/api/v2/sd/sdf:d:", "/api/v1/sdf/sdf", "/api/v1/nbs/model:d:efef:123:",
+	"/api/v1/ns/model:a%3Ab:c%2Fd:(action)", "/api/v1/ns/model:%3a:", "/api/v1/ns/:1:", "/api/v1/ns/model:", "/api/v1/ns/model:1",
+}
+
+func FuzzURISplit(f *testing.F) {
+	for _, v := range uriSeeds {
+		f.Add(v)
+	}
+
+	u, err := NewURI("/api/v1/")
+	if err != nil {
+		f.Errorf("Unexpected error '%s'", err)
+		f.FailNow()
+	}
+
+	f.Fuzz(func(t *testing.T, uri string) {
+		ns, model, action, idList, multi, err := u.Split(uri)
+		if err != nil {
+			return
+		}
+
+		if !strings.HasPrefix(uri, "/api/v1/") {
+			t.Errorf("'%s' split with out the rootPath", uri)
+		}
+
+		if multi != (len(idList) > 1) {
+			t.Errorf("multi %t wrong for '%s'", multi, uri)
+		}
+
+		r, err := u.Build(ns, model, action, idList)
+		if err != nil {
+			t.Errorf("Unexpected error '%s' building '%s'", err, uri)
+			t.FailNow()
+		}
+		if r != uri {
+			t.Errorf("Round trip failed '%s' -> '%s'", uri, r)
+		}
+	})
+}
+
+func FuzzURIExtractIds(f *testing.F) {
+	for _, v := range uriSeeds {
+		f.Add(v)
+	}
+
+	u, err := NewURI("/api/v1/")
+	if err != nil {
+		f.Errorf("Unexpected error '%s'", err)
+		f.FailNow()
+	}
+
+	f.Fuzz(func(t *testing.T, uri string) {
+		result, err := u.ExtractIds([]string{uri})
+		_, _, _, idList, _, splitErr := u.Split(uri)
+		if splitErr != nil {
+			return // ExtractIds does not check everything Split does
+		}
+
+		if err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, uri)
+			t.FailNow()
+		}
+
+		if idList == nil {
+			idList = []string{}
+		}
+		if !reflect.DeepEqual(result, idList) {
+			t.Errorf("Expected '%q' got '%q' for '%s'", idList, result, uri)
+		}
+	})
+}
+
+func FuzzURIUpdateIDs(f *testing.F) {
+	for _, v := range uriSeeds {
+		f.Add(v, "id")
+		f.Add(v, "a:b")
+	}
+
+	u, err := NewURI("/api/v1/")
+	if err != nil {
+		f.Errorf("Unexpected error '%s'", err)
+		f.FailNow()
+	}
+
+	f.Fuzz(func(t *testing.T, uri string, id string) {
+		ns, model, action, _, _, err := u.Split(uri)
+		if err != nil {
+			if _, err := u.UpdateIDs(uri, []string{id}); err == nil {
+				t.Errorf("error missing for '%s'", uri)
+			}
+			return
+		}
+
+		r, err := u.UpdateIDs(uri, []string{id})
+		if err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, uri)
+			t.FailNow()
+		}
+
+		ns2, model2, action2, idList, _, err := u.Split(r)
+		if err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, r)
+			t.FailNow()
+		}
+
+		if !reflect.DeepEqual(ns2, ns) || model2 != model || action2 != action {
+			t.Errorf("'%s' changed to '%s'", uri, r)
+		}
+
+		if model != "" && !reflect.DeepEqual(idList, []string{id}) {
+			t.Errorf("Expected ['%s'] got '%q' from '%s'", id, idList, r)
+		}
+	})
+}
+
+func FuzzURIRootPath(f *testing.F) {
+	f.Add("/api/v1/", "ns", "model", "1")
+	f.Add("/", "ns", "model", "1")
+	f.Add("/api.v1/", "ns", "model", "1")
+	f.Add("/a+b/", "ns", "model", "1")
+	f.Add("/v(1)/", "ns", "model", "1")
+
+	f.Fuzz(func(t *testing.T, rootPath string, namespace string, model string, id string) {
+		u, err := NewURI(rootPath)
+		if err != nil {
+			return
+		}
+
+		r, err := u.Build([]string{namespace}, model, "", []string{id})
+		if err != nil {
+			return
+		}
+
+		if !strings.HasPrefix(r, rootPath) {
+			t.Errorf("'%s' does not start with '%s'", r, rootPath)
+		}
+
+		ns, model2, _, idList, _, err := u.Split(r)
+		if err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, r)
+			t.FailNow()
+		}
+		if !reflect.DeepEqual(ns, []string{namespace}) || model2 != model || (model != "" && !reflect.DeepEqual(idList, []string{id})) {
+			t.Errorf("Round trip failed for '%s'", r)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	cinp "github.com/cinp/go"
+)
+
+func diffCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "output the changes as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return errors.New("expected BEFORE [AFTER]")
+	}
+
+	before, err := loadSchema(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var after *cinp.Schema
+	if flags.NArg() == 2 {
+		after, err = loadSchema(flags.Arg(1))
+	} else {
+		after, err = crawl(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	diff := cinp.DiffSchema(before, after)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(struct {
+			Breaking bool                `json:"breaking"`
+			Changes  []cinp.SchemaChange `json:"changes"`
+		}{diff.Breaking(), diff.Changes}); err != nil {
+			return err
+		}
+	} else {
+		for _, change := range diff.Changes {
+			fmt.Println(change)
+		}
+	}
+
+	if diff.Breaking() {
+		return &exitError{code: 1}
+	}
+
+	return nil
+}
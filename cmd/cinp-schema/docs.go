@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+
+	cinp "github.com/cinp/go"
+)
+
+const markdownTemplate = `# {{ .Title }}
+
+API Version: {{ .Schema.Root.APIVersion }}
+{{ with .Schema.Root.Doc }}
+{{ . }}
+{{ end }}
+## Contents
+{{ template "toc" .Schema.Root }}
+{{ range .Namespaces }}
+<a id="{{ anchor .Path }}"></a>
+## Namespace {{ .Name }}
+
+Path: ` + "`{{ .Path }}`" + `, API Version: {{ .APIVersion }}
+{{ with .Doc }}
+{{ . }}
+{{ end }}
+{{- range .Models }}
+<a id="{{ anchor .Path }}"></a>
+### Model {{ .Name }}
+
+Path: ` + "`{{ .Path }}`" + `
+{{ with .Doc }}
+{{ . }}
+{{ end }}
+{{- with .NotAllowedMethods }}
+Not Allowed Methods: {{ join . ", " }}
+{{ end }}
+#### Fields
+{{ template "fields" .Fields }}
+{{- with .Constants }}
+#### Constants
+
+| Name | Value |
+| ---- | ----- |
+{{ range $name, $value := . }}| {{ $name }} | {{ cell $value }} |
+{{ end }}{{ end }}
+{{- if .ListFilters }}
+#### List Filters
+{{ range $name, $fields := .ListFilters }}
+##### {{ $name }}
+{{ template "fields" $fields }}{{ end }}{{ end }}
+{{- with .Actions }}
+#### Actions
+{{ range . }}
+<a id="{{ anchor .Path }}"></a>
+##### {{ .Name }}{{ if .Static }} (static){{ end }}
+
+Path: ` + "`{{ .Path }}`" + `
+{{ with .Doc }}
+{{ . }}
+{{ end }}
+{{- if .Paramaters }}
+Paramaters:
+{{ template "fields" .Paramaters }}{{ end }}
+{{- if .ReturnType.Type }}
+Returns: {{ type .ReturnType }}
+{{ end }}{{ end }}{{ end }}{{ end }}{{ end }}
+
+{{- define "toc" }}
+{{ indent .Path }}- [{{ .Name }}](#{{ anchor .Path }})
+{{- range .Models }}
+{{ indent .Path }}  - [{{ .Name }}](#{{ anchor .Path }})
+{{- end }}
+{{- range .Namespaces }}{{ template "toc" . }}{{ end }}
+{{- end }}
+
+{{- define "fields" }}
+| Name | Type | Required | Mode | Default | Choices | Doc |
+| ---- | ---- | -------- | ---- | ------- | ------- | --- |
+{{ range . }}| {{ .Name }} | {{ type . }} | {{ .Required }} | {{ .Mode }} | {{ if ne .Default nil }}{{ cell .Default }}{{ end }} | {{ with .Choices }}{{ cell . }}{{ end }} | {{ cell .Doc }} |
+{{ end }}{{ end }}
+`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; vertical-align: top; }
+code { background: #eee; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>API Version: {{ .Schema.Root.APIVersion }}</p>
+{{ with .Schema.Root.Doc }}<p>{{ . }}</p>{{ end }}
+<h2>Contents</h2>
+{{ template "toc" .Schema.Root }}
+{{ range .Namespaces }}
+<h2 id="{{ anchor .Path }}">Namespace {{ .Name }}</h2>
+<p>Path: <code>{{ .Path }}</code>, API Version: {{ .APIVersion }}</p>
+{{ with .Doc }}<p>{{ . }}</p>{{ end }}
+{{ range .Models }}
+<h3 id="{{ anchor .Path }}">Model {{ .Name }}</h3>
+<p>Path: <code>{{ .Path }}</code></p>
+{{ with .Doc }}<p>{{ . }}</p>{{ end }}
+{{ with .NotAllowedMethods }}<p>Not Allowed Methods: {{ join . ", " }}</p>{{ end }}
+<h4>Fields</h4>
+{{ template "fields" .Fields }}
+{{ with .Constants }}
+<h4>Constants</h4>
+<table>
+<tr><th>Name</th><th>Value</th></tr>
+{{ range $name, $value := . }}<tr><td>{{ $name }}</td><td>{{ $value }}</td></tr>
+{{ end }}</table>
+{{ end }}
+{{ if .ListFilters }}
+<h4>List Filters</h4>
+{{ range $name, $fields := .ListFilters }}
+<h5>{{ $name }}</h5>
+{{ template "fields" $fields }}
+{{ end }}{{ end }}
+{{ with .Actions }}
+<h4>Actions</h4>
+{{ range . }}
+<h5 id="{{ anchor .Path }}">{{ .Name }}{{ if .Static }} (static){{ end }}</h5>
+<p>Path: <code>{{ .Path }}</code></p>
+{{ with .Doc }}<p>{{ . }}</p>{{ end }}
+{{ if .Paramaters }}<p>Paramaters:</p>
+{{ template "fields" .Paramaters }}{{ end }}
+{{ if .ReturnType.Type }}<p>Returns: {{ type .ReturnType }}</p>{{ end }}
+{{ end }}{{ end }}{{ end }}{{ end }}
+</body>
+</html>
+
+{{- define "toc" }}
+<ul>
+<li><a href="#{{ anchor .Path }}">{{ .Name }}</a>
+{{ if .Models }}<ul>
+{{ range .Models }}<li><a href="#{{ anchor .Path }}">{{ .Name }}</a></li>
+{{ end }}</ul>{{ end }}
+{{ range .Namespaces }}{{ template "toc" . }}{{ end }}
+</li>
+</ul>
+{{- end }}
+
+{{- define "fields" }}
+<table>
+<tr><th>Name</th><th>Type</th><th>Required</th><th>Mode</th><th>Default</th><th>Choices</th><th>Doc</th></tr>
+{{ range . }}<tr><td>{{ .Name }}</td><td>{{ type . }}</td><td>{{ .Required }}</td><td>{{ .Mode }}</td><td>{{ if ne .Default nil }}{{ .Default }}{{ end }}</td><td>{{ with .Choices }}{{ . }}{{ end }}</td><td>{{ .Doc }}</td></tr>
+{{ end }}</table>
+{{- end }}
+`
+
+type docsData struct {
+	Title      string
+	Schema     *cinp.Schema
+	Namespaces []*cinp.SchemaNamespace
+}
+
+// docsAnchor returns the id used to link to a namespace, model or action
+func docsAnchor(schema *cinp.Schema, path string) string {
+	name := schema.Name(path)
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// docsType describes a field's type, with the path of the target model if it is in the schema
+func docsType(schema *cinp.Schema, field *cinp.SchemaField) (string, string) {
+	result := field.Type
+	if field.Type == "String" && field.Length > 0 {
+		result = fmt.Sprintf("String(%d)", field.Length)
+	}
+
+	target := ""
+	if field.Type == "Model" {
+		result = "Model " + field.URI
+		if field.Model != nil {
+			result = "Model " + schema.Name(field.Model.Path)
+			target = field.Model.Path
+		}
+	}
+
+	if field.IsArray {
+		result = "[]" + result
+	}
+
+	return result, target
+}
+
+// markdownCell escapes a value for use in a table cell
+func markdownCell(value interface{}) string {
+	result := fmt.Sprintf("%v", value)
+	result = strings.ReplaceAll(result, "|", "\\|")
+	return strings.ReplaceAll(result, "\n", "<br>")
+}
+
+func writeDocs(w io.Writer, schema *cinp.Schema, format string, title string) error {
+	data := docsData{Title: title, Schema: schema}
+	schema.Walk(func(namespace *cinp.SchemaNamespace) {
+		data.Namespaces = append(data.Namespaces, namespace)
+	})
+
+	anchor := func(path string) string { return docsAnchor(schema, path) }
+
+	switch format {
+	case "markdown":
+		funcs := template.FuncMap{
+			"anchor": anchor,
+			"join":   strings.Join,
+			"cell":   markdownCell,
+			"indent": func(path string) string {
+				return strings.Repeat("  ", strings.Count(strings.TrimPrefix(path, schema.Root.Path), "/"))
+			},
+			"type": func(field *cinp.SchemaField) string {
+				result, target := docsType(schema, field)
+				if target != "" {
+					return fmt.Sprintf("[%s](#%s)", markdownCell(result), anchor(target))
+				}
+				return markdownCell(result)
+			},
+		}
+		tmpl, err := template.New("docs").Funcs(funcs).Parse(markdownTemplate)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, data)
+
+	case "html":
+		funcs := htmltemplate.FuncMap{
+			"anchor": anchor,
+			"join":   strings.Join,
+			"type": func(field *cinp.SchemaField) htmltemplate.HTML {
+				result, target := docsType(schema, field)
+				if target != "" {
+					return htmltemplate.HTML(fmt.Sprintf("<a href=\"#%s\">%s</a>", htmltemplate.HTMLEscapeString(anchor(target)), htmltemplate.HTMLEscapeString(result)))
+				}
+				return htmltemplate.HTML(htmltemplate.HTMLEscapeString(result))
+			},
+		}
+		tmpl, err := htmltemplate.New("docs").Funcs(funcs).Parse(htmlTemplate)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, data)
+	}
+
+	return fmt.Errorf("unknown format '%s'", format)
+}
+
+func docsCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	output := flags.String("o", "-", "output file")
+	format := flags.String("format", "html", "output format, html or markdown")
+	title := flags.String("title", "CInP API", "title of the API")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return errors.New("expected at most one SNAPSHOT")
+	}
+
+	schema, err := schemaFromArgs(ctx, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := writeDocs(out, schema, *format, *title); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
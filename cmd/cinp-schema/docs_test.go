@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	cinp "github.com/cinp/go"
+)
+
+const testSchema = `{"root": {"name": "root", "path": "/api/v1/", "api-version": "1.0", "namespaces": [
+  {"name": "Building", "path": "/api/v1/Building/", "api-version": "1.0", "models": [
+    {"name": "Site", "doc": "A | Site", "path": "/api/v1/Building/Site", "fields": [{"name": "name", "type": "String", "length": 40, "required": true}, {"name": "active", "type": "Boolean", "default": false}]},
+    {"name": "Room", "path": "/api/v1/Building/Room", "constants": {"MAX": "5"},
+     "fields": [{"name": "site", "type": "Model", "uri": "/api/v1/Building/Site", "doc": "where <it> is"}],
+     "list-filters": {"site": [{"name": "site", "type": "Model", "uri": "/api/v1/Building/Site"}]},
+     "actions": [{"name": "move", "path": "/api/v1/Building/Room(move)", "return-type": {"type": "Boolean"}, "paramaters": [{"name": "site", "type": "Model", "uri": "/api/v1/Building/Site", "is_array": true}]}]}
+  ]}
+]}}`
+
+func TestWriteDocs(t *testing.T) {
+	schema, err := cinp.LoadSchema(strings.NewReader(testSchema))
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	buff := &bytes.Buffer{}
+	if err := writeDocs(buff, schema, "markdown", "Test"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	for _, v := range []string{
+		"# Test",
+		"    - [Room](#Building.Room)",
+		"<a id=\"Building.Site\"></a>",
+		"| site | [Model Building.Site](#Building.Site) | false |",
+		"| site | [[]Model Building.Site](#Building.Site) | false |",
+		"| MAX | 5 |",
+		"##### site",
+		"Returns: Boolean",
+		"A | Site",
+		"| active | Boolean | false |  | false |",
+	} {
+		if !strings.Contains(buff.String(), v) {
+			t.Errorf("Markdown missing '%s' in '%s'", v, buff.String())
+			t.FailNow()
+		}
+	}
+
+	buff.Reset()
+	if err := writeDocs(buff, schema, "html", "Test <API>"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	for _, v := range []string{
+		"<title>Test &lt;API&gt;</title>",
+		"<h3 id=\"Building.Room\">Model Room</h3>",
+		"<td><a href=\"#Building.Site\">Model Building.Site</a></td>",
+		"where &lt;it&gt; is",
+		"<h5 id=\"Building.Room.move\">move</h5>",
+		"<td>false</td><td></td><td>false</td>",
+	} {
+		if !strings.Contains(buff.String(), v) {
+			t.Errorf("HTML missing '%s' in '%s'", v, buff.String())
+			t.FailNow()
+		}
+	}
+
+	if err := writeDocs(buff, schema, "pdf", "Test"); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
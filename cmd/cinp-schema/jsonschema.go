@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+
+	cinp "github.com/cinp/go"
+	"github.com/cinp/go/internal/cli"
+)
+
+func jsonSchemaCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("jsonschema", flag.ContinueOnError)
+	outputDir := flags.String("o", "", "directory to write a file per model and action to, by default all the schemas are written to stdout keyed by path")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return errors.New("expected at most one SNAPSHOT")
+	}
+
+	schema, err := schemaFromArgs(ctx, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	result := map[string]map[string]interface{}{}
+	for _, model := range schema.Models() {
+		result[model.Path] = cinp.ModelJSONSchema(model)
+		for _, action := range model.Actions {
+			result[action.Path] = cinp.ActionJSONSchema(action)
+		}
+	}
+
+	if *outputDir == "" {
+		return cli.WriteJSON(os.Stdout, result)
+	}
+
+	if err := os.MkdirAll(*outputDir, 0o755); err != nil {
+		return err
+	}
+
+	for path, value := range result {
+		file, err := os.Create(filepath.Join(*outputDir, schema.Name(path)+".json"))
+		if err != nil {
+			return err
+		}
+
+		if err := cli.WriteJSON(file, value); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+// cinp-schema crawls CInP APIs and works with the resulting schemas
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	cinp "github.com/cinp/go"
+	"github.com/cinp/go/internal/cli"
+)
+
+var commands = map[string]cli.Command{
+	"snapshot":   {Usage: "snapshot [-o FILE]\n\tcrawl the API and save the schema as JSON", Run: snapshotCommand},
+	"diff":       {Usage: "diff [-json] BEFORE [AFTER]\n\tcompare schema snapshots, AFTER defaults to the live API\n\texits 1 if there are breaking changes", Run: diffCommand},
+	"docs":       {Usage: "docs [-format html|markdown] [-o FILE] [-title TITLE] [SNAPSHOT]\n\twrite documentation for the snapshot or the live API", Run: docsCommand},
+	"jsonschema": {Usage: "jsonschema [-o DIR] [SNAPSHOT]\n\twrite JSON Schemas for the models and actions of the snapshot or the live API", Run: jsonSchemaCommand},
+	"openapi":    {Usage: "openapi [-format json|yaml] [-o FILE] [-title TITLE] [-server URL] [-version VERSION] [SNAPSHOT]\n\twrite a OpenAPI document for the snapshot or the live API", Run: openAPICommand},
+}
+
+var (
+	host        string
+	rootPath    string
+	headers     = cli.HeaderList{}
+	parallelism int
+	debug       bool
+)
+
+func usage() {
+	cli.Usage(commands, "")
+}
+
+func envDefault(name string, value string) string {
+	if result := os.Getenv(name); result != "" {
+		return result
+	}
+	return value
+}
+
+func newClient() (*cinp.CInP, error) {
+	if host == "" {
+		return nil, errors.New("host is required, use -host or CINP_HOST")
+	}
+
+	level := slog.LevelWarn
+	if debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	client, err := cinp.NewCInP(logger, host, rootPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range headers {
+		client.SetHeader(name, value)
+	}
+
+	return client, nil
+}
+
+func crawl(ctx context.Context) (*cinp.Schema, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Crawl(ctx, rootPath, parallelism)
+}
+
+// schemaFromArgs loads the snapshot if there is one in args, otherwise crawls the live API
+func schemaFromArgs(ctx context.Context, args []string) (*cinp.Schema, error) {
+	if len(args) > 0 {
+		return loadSchema(args[0])
+	}
+
+	return crawl(ctx)
+}
+
+// loadSchema loads a saved schema, "-" for stdin
+func loadSchema(path string) (*cinp.Schema, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	return cinp.LoadSchema(reader)
+}
+
+// createOutput opens path for writing, "" or "-" for stdout
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+
+	return os.Create(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// exitError is returned by commands that need a exit code other than 2
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string { return fmt.Sprintf("exit code %d", e.code) }
+
+func main() {
+	flag.Usage = usage
+	flag.StringVar(&host, "host", envDefault("CINP_HOST", ""), "API host, ie http://localhost:8080 (env CINP_HOST)")
+	flag.StringVar(&rootPath, "root", envDefault("CINP_ROOT_PATH", "/api/v1/"), "API root path (env CINP_ROOT_PATH)")
+	flag.Var(headers, "header", "extra request header in the form Name=Value, may be repeated")
+	flag.IntVar(&parallelism, "parallelism", 4, "max concurrent describe requests")
+	flag.BoolVar(&debug, "debug", false, "debug logging")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd.Run(context.Background(), flag.Args()[1:])
+	if err != nil {
+		exitErr := &exitError{}
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", flag.Arg(0), err)
+		os.Exit(2)
+	}
+}
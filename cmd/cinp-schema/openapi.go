@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	cinp "github.com/cinp/go"
+)
+
+func openAPICommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	output := flags.String("o", "-", "output file")
+	format := flags.String("format", "json", "output format, json or yaml")
+	title := flags.String("title", "CInP API", "title of the API")
+	server := flags.String("server", "", "server URL to include in the document, defaults to the host")
+	version := flags.String("version", cinp.OpenAPIVersion, "OpenAPI version, "+cinp.OpenAPIVersion+" or "+cinp.OpenAPIVersion32+" for the CInP methods as additionalOperations")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return errors.New("expected at most one SNAPSHOT")
+	}
+
+	schema, err := schemaFromArgs(ctx, flags.Args())
+	if err != nil {
+		return err
+	}
+
+	serverURL := *server
+	if serverURL == "" {
+		serverURL = host
+	}
+
+	document, err := cinp.NewOpenAPIVersion(schema, *title, serverURL, *version)
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFormat(out, *format, document); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cinp/go/internal/cli"
+)
+
+func writeFormat(w io.Writer, format string, value interface{}) error {
+	switch format {
+	case "json":
+		return cli.WriteJSON(w, value)
+	case "yaml":
+		return cli.WriteYAML(w, value)
+	}
+
+	return fmt.Errorf("unknown format '%s'", format)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"flag"
+)
+
+func snapshotCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	output := flags.String("o", "-", "output file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	schema, err := crawl(ctx)
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Save(out); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
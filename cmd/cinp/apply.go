@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	cinp "github.com/cinp/go"
+	"github.com/cinp/go/internal/cli"
+)
+
+// loadManifests loads and joins the JSON or YAML manifests, "-" is stdin
+func loadManifests(pathList []string) (*cinp.Manifest, error) {
+	if len(pathList) == 0 {
+		return nil, errors.New("at least one manifest is required")
+	}
+
+	result := &cinp.Manifest{}
+	for _, path := range pathList {
+		var buff []byte
+		var err error
+		if path == "-" {
+			buff, err = io.ReadAll(os.Stdin)
+		} else {
+			buff, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		values, err := parseValues(buff)
+		if err != nil {
+			return nil, fmt.Errorf("manifest '%s': %w", path, err)
+		}
+
+		buff, err = json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("manifest '%s': %w", path, err)
+		}
+
+		manifest, err := cinp.LoadManifest(bytes.NewReader(buff))
+		if err != nil {
+			return nil, fmt.Errorf("manifest '%s': %w", path, err)
+		}
+		result.Objects = append(result.Objects, manifest.Objects...)
+	}
+
+	return result, nil
+}
+
+// makePlan loads the manifests and plans them against the server
+func makePlan(ctx context.Context, client *cinp.CInP, pathList []string, prune bool) (*cinp.Plan, error) {
+	manifest, err := loadManifests(pathList)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Plan(ctx, manifest, prune)
+}
+
+func writePlan(w io.Writer, plan *cinp.Plan) error {
+	switch format {
+	case "json":
+		return cli.WriteJSON(w, plan)
+	case "yaml":
+		return cli.WriteYAML(w, plan)
+	case "table":
+		return plan.Write(w)
+	}
+
+	return fmt.Errorf("unknown format '%s'", format)
+}
+
+func planCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("plan", flag.ContinueOnError)
+	prune := flags.Bool("prune", false, "delete the objects of the models in the manifests that are not in the manifests")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := makePlan(ctx, client, flags.Args(), *prune)
+	if err != nil {
+		return err
+	}
+
+	return writePlan(os.Stdout, plan)
+}
+
+// confirm asks on stderr and reads the answer from stdin, only "y" and "yes" are yes
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func applyCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	prune := flags.Bool("prune", false, "delete the objects of the models in the manifests that are not in the manifests")
+	dryRun := flags.Bool("dry-run", false, "show the plan with out applying it")
+	yes := flags.Bool("yes", false, "apply with out asking")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	for _, path := range flags.Args() {
+		if path == "-" && !*yes && !*dryRun {
+			return errors.New("-yes is required when the manifest is from stdin")
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := makePlan(ctx, client, flags.Args(), *prune)
+	if err != nil {
+		return err
+	}
+
+	if err := writePlan(os.Stdout, plan); err != nil {
+		return err
+	}
+
+	if *dryRun || !plan.HasChanges() {
+		return nil
+	}
+
+	if !*yes {
+		ok, err := confirm("Apply?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("not applied")
+		}
+	}
+
+	return client.Apply(ctx, plan, func(item cinp.PlanItem, err error) {
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "%s %s: done\n", item.Action, item.URI)
+		}
+	})
+}
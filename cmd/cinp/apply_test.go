@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	cinp "github.com/cinp/go"
+)
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	sites := filepath.Join(dir, "sites.yaml")
+	rooms := filepath.Join(dir, "rooms.json")
+	racks := filepath.Join(dir, "racks.yaml")
+	if err := os.WriteFile(sites, []byte("objects:\n  - model: /api/v1/Building/Site\n    id: main\n    id_field: name\n    values:\n      name: main\n      size: 4\n"), 0o600); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if err := os.WriteFile(rooms, []byte(`{"objects": [{"model": "/api/v1/Building/Room", "id": "1", "values": {"site": "/api/v1/Building/Site:main:"}}]}`), 0o600); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := os.WriteFile(racks, []byte("objects:\n  - model: /api/v1/Building/Rack\n    id: 2\n    values:\n      room: \"/api/v1/Building/Room:1:\"\n"), 0o600); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	manifest, err := loadManifests([]string{sites, rooms, racks})
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	expected := []cinp.ManifestObject{
+		{Model: "/api/v1/Building/Site", Id: "main", IdField: "name", Values: map[string]interface{}{"name": "main", "size": json.Number("4")}},
+		{Model: "/api/v1/Building/Room", Id: "1", Values: map[string]interface{}{"site": "/api/v1/Building/Site:main:"}},
+		{Model: "/api/v1/Building/Rack", Id: "2", Values: map[string]interface{}{"room": "/api/v1/Building/Room:1:"}},
+	}
+	if !reflect.DeepEqual(manifest.Objects, expected) {
+		t.Errorf("Expected '%v' got '%v'", expected, manifest.Objects)
+		t.FailNow()
+	}
+
+	if err := os.WriteFile(rooms, []byte("objects:\n  - model: /api/v1/Building/Room\n    ids: 1\n"), 0o600); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, err := loadManifests([]string{rooms}); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	if _, err := loadManifests(nil); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	cinp "github.com/cinp/go"
+)
+
+// parseCommand parses the flags of a command which takes one URI
+func parseCommand(flags *flag.FlagSet, args []string) (string, error) {
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+
+	if flags.NArg() != 1 {
+		return "", errors.New("one URI is required")
+	}
+
+	return flags.Arg(0), nil
+}
+
+// isMulti is true if uri is of more than one object
+func isMulti(client *cinp.CInP, uri string) (bool, error) {
+	parsed, err := client.GetURI().Parse(uri)
+	if err != nil {
+		return false, err
+	}
+
+	return parsed.IsMulti, nil
+}
+
+// fieldType is the type of the field for display, ie "[]Model /api/v1/Building/Site"
+func fieldType(field cinp.FieldParamater) string {
+	result := field.Type
+	if field.IsArray {
+		result = "[]" + result
+	}
+	if field.URI != "" {
+		result += " " + field.URI
+	}
+	return result
+}
+
+type describeOutput struct {
+	Type string `json:"type"`
+	*cinp.Describe
+}
+
+func describeTable(describeType string, describe *cinp.Describe) *table {
+	result := &table{header: []string{"KIND", "NAME", "TYPE", "DOC"}}
+	switch describeType {
+	case "Namespace":
+		for _, item := range describe.Namespaces {
+			result.add("namespace", item, "", "")
+		}
+		for _, item := range describe.Models {
+			result.add("model", item, "", "")
+		}
+
+	case "Model":
+		for _, field := range describe.Fields {
+			result.add("field", field.Name, fieldType(field), field.Doc)
+		}
+		for _, name := range sortedKeys(describe.ListFilters) {
+			paramList := []string{}
+			for _, field := range describe.ListFilters[name] {
+				paramList = append(paramList, field.Name+" "+fieldType(field))
+			}
+			result.add("filter", name, strings.Join(paramList, ", "), "")
+		}
+		for _, item := range describe.Actions {
+			result.add("action", item, "", "")
+		}
+		for _, name := range sortedKeys(describe.Constants) {
+			result.add("constant", name, describe.Constants[name], "")
+		}
+
+	case "Action":
+		for _, field := range describe.Paramaters {
+			result.add("paramater", field.Name, fieldType(field), field.Doc)
+		}
+		if describe.ReturnType.Type != "" {
+			result.add("return", "", fieldType(describe.ReturnType), describe.ReturnType.Doc)
+		}
+	}
+
+	return result
+}
+
+func describeCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("describe", flag.ContinueOnError)
+	uri, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	describe, describeType, err := client.Describe(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(os.Stdout, describeOutput{Type: describeType, Describe: describe}, func() *table {
+		return describeTable(describeType, describe)
+	})
+}
+
+type listOutput struct {
+	URIs     []string `json:"uris"`
+	Position int      `json:"position"`
+	Count    int      `json:"count"`
+	Total    int      `json:"total"`
+}
+
+func listCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	filterName := flags.String("filter", "", "list filter")
+	input := addInputFlags(flags)
+	position := flags.Int("position", 0, "position to list from")
+	count := flags.Int("count", 50, "number of objects to list")
+	all := flags.Bool("all", false, "list all the objects, count is the page size")
+	uri, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	filter, err := client.NewListFilter(ctx, uri, *filterName)
+	if err != nil {
+		return err
+	}
+
+	values, err := input.readValues(false)
+	if err != nil {
+		return err
+	}
+	if len(values) > 0 && *filterName == "" {
+		return errors.New("filter values can only be used with -filter")
+	}
+	for name, value := range values {
+		if err := filter.Set(name, value); err != nil {
+			return err
+		}
+	}
+
+	result := listOutput{URIs: []string{}}
+	for {
+		uriList, pagePosition, pageCount, total, err := filter.List(ctx, *position, *count)
+		if err != nil {
+			return err
+		}
+		result.URIs = append(result.URIs, uriList...)
+		result.Total = total
+		if !*all {
+			result.Position = pagePosition
+			result.Count = pageCount
+			break
+		}
+
+		*position = pagePosition + pageCount
+		if pageCount == 0 || *position >= total {
+			result.Count = len(result.URIs)
+			break
+		}
+	}
+
+	return writeOutput(os.Stdout, result, func() *table {
+		t := &table{header: []string{"URI"}}
+		for _, item := range result.URIs {
+			t.add(item)
+		}
+		return t
+	})
+}
+
+// objectValues is the values of the objects by URI
+func objectValues(objects map[string]cinp.Object) (map[string]map[string]interface{}, error) {
+	result := make(map[string]map[string]interface{}, len(objects))
+	for uri, object := range objects {
+		mo, err := cinp.NewMappedObject(object)
+		if err != nil {
+			return nil, err
+		}
+		result[uri] = mo.Data
+	}
+
+	return result, nil
+}
+
+func writeObjects(objects map[string]cinp.Object) error {
+	result, err := objectValues(objects)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(os.Stdout, result, func() *table { return objectTable(result) })
+}
+
+func getCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	uri, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	multi, err := isMulti(client, uri)
+	if err != nil {
+		return err
+	}
+
+	if multi {
+		objects, err := client.GetMulti(ctx, uri)
+		if err != nil {
+			return err
+		}
+		return writeObjects(objects)
+	}
+
+	object, err := client.Get(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	return writeObjects(map[string]cinp.Object{uri: *object})
+}
+
+func createCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	input := addInputFlags(flags)
+	uri, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	values, err := input.readValues(true)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	object, err := client.Create(ctx, uri, &cinp.MappedObject{Data: values})
+	if err != nil {
+		return err
+	}
+
+	return writeObjects(map[string]cinp.Object{(*object).GetURI(): *object})
+}
+
+func updateCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("update", flag.ContinueOnError)
+	input := addInputFlags(flags)
+	uri, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	values, err := input.readValues(true)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	multi, err := isMulti(client, uri)
+	if err != nil {
+		return err
+	}
+
+	if multi {
+		objects := map[string]cinp.Object{}
+		if err := client.UpdateMulti(ctx, uri, &values, &objects); err != nil {
+			return err
+		}
+		return writeObjects(objects)
+	}
+
+	mo := &cinp.MappedObject{Data: values}
+	mo.SetURI(uri)
+	object, err := client.Update(ctx, mo)
+	if err != nil {
+		return err
+	}
+
+	return writeObjects(map[string]cinp.Object{uri: *object})
+}
+
+func deleteCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("delete", flag.ContinueOnError)
+	uri, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteURI(ctx, uri)
+}
+
+// valueTable is a table of a value, a row per key for maps and per item for lists
+func valueTable(value interface{}) *table {
+	result := &table{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result.header = []string{"KEY", "VALUE"}
+		for _, key := range sortedKeys(v) {
+			result.add(key, v[key])
+		}
+
+	case []interface{}:
+		result.header = []string{"VALUE"}
+		for _, item := range v {
+			result.add(item)
+		}
+
+	default:
+		result.add(v)
+	}
+
+	return result
+}
+
+func callCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("call", flag.ContinueOnError)
+	input := addInputFlags(flags)
+	uri, err := parseCommand(flags, args)
+	if err != nil {
+		return err
+	}
+
+	values, err := input.readValues(false)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	multi, err := isMulti(client, uri)
+	if err != nil {
+		return err
+	}
+
+	if !multi {
+		var result interface{}
+		if err := client.CallAction(ctx, uri, values, &result); err != nil {
+			return err
+		}
+		return writeOutput(os.Stdout, result, func() *table { return valueTable(result) })
+	}
+
+	results, err := cinp.CallMultiAs[interface{}](ctx, client, uri, values)
+	if err != nil {
+		return err
+	}
+
+	output := map[string]interface{}{}
+	errList := []error{}
+	for objectURI, item := range results {
+		if item.Err != nil {
+			errList = append(errList, item.Err)
+			continue
+		}
+		output[objectURI] = item.Value
+	}
+
+	if err := writeOutput(os.Stdout, output, func() *table { return valueTable(output) }); err != nil {
+		return err
+	}
+
+	if len(errList) > 0 {
+		return fmt.Errorf("%d result(s) failed: %w", len(errList), errors.Join(errList...))
+	}
+
+	return nil
+}
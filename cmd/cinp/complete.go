@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	cinp "github.com/cinp/go"
+)
+
+// completer completes the shell's commands from the names in the describes of the current namespace or model
+type completer struct {
+	sh  *shell
+	ctx context.Context
+}
+
+// Do is readline's AutoCompleter, it returns the rest of each candidate for the word being typed
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	args := strings.Fields(text)
+	word := ""
+	if len(args) > 0 && !strings.HasSuffix(text, " ") {
+		word = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	result := [][]rune{}
+	for _, candidate := range c.candidates(args, word) {
+		if strings.HasPrefix(candidate, word) {
+			result = append(result, []rune(candidate[len(word):]))
+		}
+	}
+
+	return result, len([]rune(word))
+}
+
+// candidates returns the possible values of word, args are the words before it
+func (c *completer) candidates(args []string, word string) []string {
+	if len(args) == 0 {
+		return sortedKeys(replCommands)
+	}
+
+	switch args[0] {
+	case "cd":
+		if len(args) == 1 {
+			return c.paths(word)
+		}
+
+	case "describe":
+		if len(args) == 1 {
+			return append(c.paths(word), c.actions()...)
+		}
+
+	case "call":
+		if len(args) == 1 {
+			return c.actions()
+		}
+
+	case "ls":
+		return c.listArgs(args[1:])
+
+	case "get":
+		return c.getArgs(args[1:], word)
+	}
+
+	return nil
+}
+
+// paths returns the names in the namespace of the directory part of word, namespaces end in "/".  The
+// directory part is kept so the candidates match word.
+func (c *completer) paths(word string) []string {
+	dir := ""
+	if offset := strings.LastIndexByte(word, '/'); offset != -1 {
+		dir = word[:offset+1]
+	}
+
+	uri, err := c.sh.resolve(c.ctx, dir)
+	if err != nil {
+		return nil
+	}
+	if strings.HasPrefix(dir, "/") {
+		uri = dir
+	}
+
+	describe, describeType, err := c.sh.describe(c.ctx, uri)
+	if err != nil || describeType != "Namespace" {
+		return nil
+	}
+
+	result := []string{dir + "../"}
+	for _, item := range describe.Namespaces {
+		result = append(result, dir+name(item)+"/")
+	}
+	for _, item := range describe.Models {
+		result = append(result, dir+name(item))
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// model returns the describe of the current model, nil if the current directory is not a model
+func (c *completer) model() *cinp.Describe {
+	describe, describeType, err := c.sh.describe(c.ctx, c.sh.cwd)
+	if err != nil || describeType != "Model" {
+		return nil
+	}
+
+	return describe
+}
+
+func (c *completer) actions() []string {
+	model := c.model()
+	if model == nil {
+		return nil
+	}
+
+	result := []string{}
+	for _, item := range model.Actions {
+		result = append(result, name(item))
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// getArgs completes the field names after -fields, word is the comma separated fields so far
+func (c *completer) getArgs(args []string, word string) []string {
+	if len(args) == 0 {
+		return []string{"-fields"}
+	}
+	if args[len(args)-1] != "-fields" {
+		return nil
+	}
+
+	model := c.model()
+	if model == nil {
+		return nil
+	}
+
+	done := word[:strings.LastIndexByte(word, ',')+1]
+	result := []string{}
+	for _, field := range model.Fields {
+		result = append(result, done+field.Name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// listArgs completes ls's flags, the filter names after -filter and "FIELD=" for the filter's fields
+func (c *completer) listArgs(args []string) []string {
+	model := c.model()
+	if model == nil {
+		return nil
+	}
+
+	if len(args) > 0 && args[len(args)-1] == "-filter" {
+		return sortedKeys(model.ListFilters)
+	}
+
+	filterName := ""
+	for i, arg := range args {
+		if arg == "-filter" && i+1 < len(args) {
+			filterName = args[i+1]
+		}
+	}
+
+	if filterName == "" {
+		return []string{"-count", "-filter", "-position"}
+	}
+
+	result := []string{}
+	for _, field := range model.ListFilters[filterName] {
+		result = append(result, field.Name+"=")
+	}
+	sort.Strings(result)
+
+	return result
+}
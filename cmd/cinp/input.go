@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inputFlags are the flags for the values of a command, see readValues
+type inputFlags struct {
+	data *string
+	file *string
+}
+
+func addInputFlags(flags *flag.FlagSet) inputFlags {
+	return inputFlags{
+		data: flags.String("data", "", "values as JSON or YAML"),
+		file: flags.String("file", "", "file of the values as JSON or YAML, - for stdin"),
+	}
+}
+
+// stdinIsPiped is true if stdin is not a terminal
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// readValues reads the values from -data, -file or stdin, in that order.  stdin is only read with out -file -
+// when required is true and stdin is piped, so commands with optional values don't take the input of a
+// surrounding script.  nil if there are no values and required is false.
+func (f inputFlags) readValues(required bool) (map[string]interface{}, error) {
+	var buff []byte
+	var err error
+	switch {
+	case *f.data != "" && *f.file != "":
+		return nil, errors.New("only one of -data and -file can be used")
+
+	case *f.data != "":
+		buff = []byte(*f.data)
+
+	case *f.file == "-" || (*f.file == "" && required && stdinIsPiped()):
+		buff, err = io.ReadAll(os.Stdin)
+
+	case *f.file != "":
+		buff, err = os.ReadFile(*f.file)
+
+	default:
+		if required {
+			return nil, errors.New("values are required, use -data, -file or stdin")
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseValues(buff)
+}
+
+// parseValues parses a JSON or YAML map, JSON being YAML
+func parseValues(buff []byte) (map[string]interface{}, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(buff, &value); err != nil {
+		return nil, fmt.Errorf("unable to parse values: %w", err)
+	}
+
+	if value == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	result, ok := normalize(value).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("values must be a map, got %T", value)
+	}
+
+	return result, nil
+}
+
+// normalize converts the map[interface{}]interface{} YAML can decode to map[string]interface{} so it can be
+// encoded as JSON
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprintf("%v", key)] = normalize(item)
+		}
+		return result
+
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	}
+
+	return value
+}
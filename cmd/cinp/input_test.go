@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		`{"name": "bob", "size": 4}`:         {"name": "bob", "size": 4},
+		"name: bob\nsize: 4\ntags: [a, b]\n": {"name": "bob", "size": 4, "tags": []interface{}{"a", "b"}},
+		"extra:\n  1: one\n":                 {"extra": map[string]interface{}{"1": "one"}},
+		"":                                   {},
+	}
+	for value, expected := range tests {
+		result, err := parseValues([]byte(value))
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Expected '%v' got '%v' for '%s'", expected, result, value)
+			t.FailNow()
+		}
+	}
+
+	for _, value := range []string{"[1, 2]", "{bad", "bob"} {
+		if _, err := parseValues([]byte(value)); err == nil {
+			t.Errorf("error missing for '%s'", value)
+			t.FailNow()
+		}
+	}
+}
+
+func TestReadValues(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, err := writer.WriteString("name: bob\n"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	writer.Close()
+
+	stdin := os.Stdin
+	os.Stdin = reader
+	defer func() { os.Stdin = stdin; reader.Close() }()
+
+	data := ""
+	file := ""
+	input := inputFlags{data: &data, file: &file}
+
+	// not required, piped stdin is left alone
+	values, err := input.readValues(false)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if values != nil {
+		t.Errorf("Expected nil got '%v'", values)
+		t.FailNow()
+	}
+
+	values, err = input.readValues(true)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(values, map[string]interface{}{"name": "bob"}) {
+		t.Errorf("Expected the stdin values got '%v'", values)
+		t.FailNow()
+	}
+
+	data = "size: 4"
+	values, err = input.readValues(false)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(values, map[string]interface{}{"size": 4}) {
+		t.Errorf("Expected the -data values got '%v'", values)
+		t.FailNow()
+	}
+}
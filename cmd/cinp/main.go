@@ -0,0 +1,180 @@
+// cinp is a command line client for CInP APIs
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	cinp "github.com/cinp/go"
+	"github.com/cinp/go/internal/cli"
+	"gopkg.in/yaml.v3"
+)
+
+var commands = map[string]cli.Command{
+	"describe": {Usage: "describe URI\n\tdescribe the namespace, model or action", Run: describeCommand},
+	"list":     {Usage: "list [-filter NAME] [-data VALUES] [-file FILE] [-position N] [-count N] [-all] URI\n\tlist the objects of the model, VALUES are the filter values", Run: listCommand},
+	"get":      {Usage: "get URI\n\tget the object(s)", Run: getCommand},
+	"create":   {Usage: "create [-data VALUES] [-file FILE] URI\n\tcreate a object in the model", Run: createCommand},
+	"update":   {Usage: "update [-data VALUES] [-file FILE] URI\n\tupdate the object(s)", Run: updateCommand},
+	"delete":   {Usage: "delete URI\n\tdelete the object(s)", Run: deleteCommand},
+	"call":     {Usage: "call [-data ARGS] [-file FILE] URI\n\tcall the action", Run: callCommand},
+	"shell":    {Usage: "shell [PATH]\n\tinteractive shell, starting in the namespace or model", Run: shellCommand},
+	"plan":     {Usage: "plan [-prune] MANIFEST ...\n\tshow the changes to make the server match the manifests", Run: planCommand},
+	"apply":    {Usage: "apply [-prune] [-dry-run] [-yes] MANIFEST ...\n\tmake the server match the manifests, asking first unless -yes", Run: applyCommand},
+}
+
+// config is the settings from the config file, flags and the environment take precedence
+type config struct {
+	Host      string            `yaml:"host"`
+	RootPath  string            `yaml:"root_path"`
+	AuthID    string            `yaml:"auth_id"`
+	AuthToken string            `yaml:"auth_token"`
+	Headers   map[string]string `yaml:"headers"`
+	Format    string            `yaml:"format"`
+}
+
+var (
+	configPath string
+	host       string
+	rootPath   string
+	authID     string
+	authToken  string
+	headers    = cli.HeaderList{}
+	format     string
+	debug      bool
+)
+
+func usage() {
+	cli.Usage(commands, "VALUES and ARGS are JSON or YAML, from -data or -file, create and update also read piped stdin.")
+}
+
+// firstSet returns the first value that is not ""
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// defaultConfigPath is $XDG_CONFIG_HOME/cinp/config.yaml or the OS equivalent, "" if there is no config dir
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cinp", "config.yaml")
+}
+
+// loadConfig loads the config file at path, a missing file is only an error if required is true
+func loadConfig(path string, required bool) (*config, error) {
+	result := &config{}
+	if path == "" {
+		return result, nil
+	}
+
+	buff, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(buff, result); err != nil {
+		return nil, fmt.Errorf("config file '%s': %w", path, err)
+	}
+
+	return result, nil
+}
+
+// applyConfig fills in the settings that where not set by flag from the environment, then the config file
+func applyConfig() error {
+	path := firstSet(configPath, os.Getenv("CINP_CONFIG"))
+	cfg, err := loadConfig(firstSet(path, defaultConfigPath()), path != "")
+	if err != nil {
+		return err
+	}
+
+	host = firstSet(host, os.Getenv("CINP_HOST"), cfg.Host)
+	rootPath = firstSet(rootPath, os.Getenv("CINP_ROOT_PATH"), cfg.RootPath, "/api/v1/")
+	authID = firstSet(authID, os.Getenv("CINP_AUTH_ID"), cfg.AuthID)
+	authToken = firstSet(authToken, os.Getenv("CINP_AUTH_TOKEN"), cfg.AuthToken)
+	format = firstSet(format, os.Getenv("CINP_FORMAT"), cfg.Format, "table")
+	for name, value := range cfg.Headers {
+		if _, ok := headers[name]; !ok {
+			headers[name] = value
+		}
+	}
+
+	return nil
+}
+
+func newClient() (*cinp.CInP, error) {
+	if host == "" {
+		return nil, errors.New("host is required, use -host, CINP_HOST or the config file")
+	}
+
+	level := slog.LevelWarn
+	if debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	client, err := cinp.NewCInP(logger, host, rootPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if authID != "" {
+		client.SetHeader("Auth-Id", authID)
+		client.SetHeader("Auth-Token", authToken)
+	}
+
+	for name, value := range headers {
+		client.SetHeader(name, value)
+	}
+
+	return client, nil
+}
+
+func main() {
+	flag.Usage = usage
+	flag.StringVar(&configPath, "config", "", "config file (env CINP_CONFIG, default "+defaultConfigPath()+")")
+	flag.StringVar(&host, "host", "", "API host, ie http://localhost:8080 (env CINP_HOST)")
+	flag.StringVar(&rootPath, "root", "", "API root path, default /api/v1/ (env CINP_ROOT_PATH)")
+	flag.StringVar(&authID, "auth-id", "", "Auth-Id header (env CINP_AUTH_ID)")
+	flag.StringVar(&authToken, "auth-token", "", "Auth-Token header (env CINP_AUTH_TOKEN)")
+	flag.Var(headers, "header", "extra request header in the form Name=Value, may be repeated")
+	flag.StringVar(&format, "format", "", "output format json, yaml or table, default table (env CINP_FORMAT)")
+	flag.BoolVar(&debug, "debug", false, "debug logging")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	if err := applyConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+
+	if err := cmd.Run(context.Background(), flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
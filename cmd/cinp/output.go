@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cinp/go/internal/cli"
+)
+
+// table is rows of cells under a header
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cells ...interface{}) {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = cellString(cell)
+	}
+	t.rows = append(t.rows, row)
+}
+
+func (t *table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(t.header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// cellString is strings as is, nil as "", and everything else as compact JSON
+func cellString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.NewReplacer("\t", " ", "\n", " ").Replace(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	buff, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(buff)
+}
+
+// objectTable is a table of objects by URI, one row per object with a column for each field, in URI order
+func objectTable(objects map[string]map[string]interface{}) *table {
+	uriList := sortedKeys(objects)
+
+	fieldSet := map[string]bool{}
+	for _, values := range objects {
+		for name := range values {
+			fieldSet[name] = true
+		}
+	}
+	fieldList := sortedKeys(fieldSet)
+
+	result := &table{header: append([]string{"URI"}, fieldList...)}
+	for _, uri := range uriList {
+		row := []interface{}{uri}
+		for _, name := range fieldList {
+			row = append(row, objects[uri][name])
+		}
+		result.add(row...)
+	}
+
+	return result
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	result := make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// writeOutput writes value in the output format, makeTable is for the table format
+func writeOutput(w io.Writer, value interface{}, makeTable func() *table) error {
+	switch format {
+	case "json":
+		return cli.WriteJSON(w, value)
+	case "yaml":
+		return cli.WriteYAML(w, value)
+	case "table":
+		return makeTable().write(w)
+	}
+
+	return fmt.Errorf("unknown format '%s'", format)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	cinp "github.com/cinp/go"
+)
+
+func TestObjectTable(t *testing.T) {
+	objects := map[string]map[string]interface{}{
+		"/api/v1/Building/Site:2:": {"name": "two", "tags": []interface{}{"a"}},
+		"/api/v1/Building/Site:1:": {"name": "one", "size": json.Number("9007199254740993")},
+	}
+
+	buff := &bytes.Buffer{}
+	if err := objectTable(objects).write(buff); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	expected := "URI                       name  size              tags\n" +
+		"/api/v1/Building/Site:1:  one   9007199254740993  \n" +
+		"/api/v1/Building/Site:2:  two                     [\"a\"]\n"
+	if buff.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, buff.String())
+		t.FailNow()
+	}
+}
+
+func TestDescribeTable(t *testing.T) {
+	describe := &cinp.Describe{
+		Fields:      []cinp.FieldParamater{{Name: "site", Type: "Model", URI: "/api/v1/Building/Site", IsArray: true, Doc: "where"}},
+		ListFilters: map[string][]cinp.FieldParamater{"site": {{Name: "site", Type: "Model", URI: "/api/v1/Building/Site"}}},
+		Actions:     []string{"/api/v1/Building/Room(move)"},
+	}
+
+	buff := &bytes.Buffer{}
+	if err := describeTable("Model", describe).write(buff); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	expected := "KIND    NAME                         TYPE                              DOC\n" +
+		"field   site                         []Model /api/v1/Building/Site     where\n" +
+		"filter  site                         site Model /api/v1/Building/Site  \n" +
+		"action  /api/v1/Building/Room(move)                                    \n"
+	if buff.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, buff.String())
+		t.FailNow()
+	}
+}
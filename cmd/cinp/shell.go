@@ -0,0 +1,763 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/chzyer/readline"
+	cinp "github.com/cinp/go"
+	"github.com/cinp/go/internal/cli"
+	"gopkg.in/yaml.v3"
+)
+
+// shell is the state of the interactive shell, the current namespace or model is cwd
+type shell struct {
+	client   *cinp.CInP
+	uri      *cinp.URI
+	describe func(ctx context.Context, uri string) (*cinp.Describe, string, error)
+	cwd      string
+	out      io.Writer
+	input    func(prompt string) (string, error) // reads a line for the paramater prompts of call
+}
+
+type replCommand struct {
+	usage string
+	run   func(sh *shell, ctx context.Context, args []string) error
+}
+
+var replCommands map[string]replCommand
+
+func init() { // in init as the commands refer to replCommands through help
+	replCommands = map[string]replCommand{
+		"cd":       {"cd [PATH]\n\tchange to the namespace or model, .. is up, / or no PATH is the root", (*shell).cd},
+		"ls":       {"ls [-filter NAME [FIELD=VALUE ...]] [-position N] [-count N]\n\tlist the namespaces and models, or the ids of the objects of the model", (*shell).ls},
+		"describe": {"describe [PATH]\n\tdescribe the namespace, model or action", (*shell).describeCmd},
+		"get":      {"get [-fields NAME,...] ID|URI [ID ...]\n\tget the object(s), only showing the fields if -fields is used", (*shell).get},
+		"call":     {"call ACTION|URI [ID ...]\n\tcall the action, prompting for the paramaters", (*shell).call},
+		"edit":     {"edit ID|URI\n\tedit the object in $EDITOR, the changed fields are updated", (*shell).edit},
+		"help":     {"help\n\tthis help", (*shell).help},
+		"exit":     {"exit\n\tleave the shell, also ^D", nil},
+	}
+}
+
+// name is the last part of a namespace, model or action path
+func name(path string) string {
+	if start := strings.IndexByte(path, '('); start != -1 {
+		return strings.TrimSuffix(path[start+1:], ")")
+	}
+
+	path = strings.TrimSuffix(path, "/")
+	return path[strings.LastIndexByte(path, '/')+1:]
+}
+
+func (sh *shell) parse(uri string) (cinp.ParsedURI, error) {
+	return sh.uri.Parse(uri)
+}
+
+// resolve returns the URI of path relative to cwd, path can be absolute, ".." or a / separated list of
+// namespace and model names
+func (sh *shell) resolve(ctx context.Context, path string) (string, error) {
+	if path == "" || path == "." {
+		return sh.cwd, nil
+	}
+
+	current := sh.cwd
+	if strings.HasPrefix(path, "/") {
+		if path == "/" {
+			return sh.uri.Build(nil, "", "", nil)
+		}
+		if _, err := sh.parse(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	for _, part := range strings.Split(strings.TrimSuffix(path, "/"), "/") {
+		parsed, err := sh.parse(current)
+		if err != nil {
+			return "", err
+		}
+
+		switch part {
+		case ".", "":
+			continue
+
+		case "..":
+			if parent, ok := parsed.ParentNamespace(); ok {
+				current = parent.String()
+			}
+			continue
+		}
+
+		if !parsed.IsNamespace() {
+			return "", fmt.Errorf("'%s' is a model, it has no '%s'", current, part)
+		}
+
+		describe, _, err := sh.describe(ctx, current)
+		if err != nil {
+			return "", err
+		}
+
+		found := ""
+		for _, item := range append(append([]string{}, describe.Namespaces...), describe.Models...) {
+			if name(item) == part {
+				found = item
+				break
+			}
+		}
+		if found == "" {
+			return "", fmt.Errorf("'%s' not found in '%s'", part, current)
+		}
+		current = found
+	}
+
+	return current, nil
+}
+
+// objectURI returns the URI of the objects with the ids in the current model, or the URI if target is a URI
+func (sh *shell) objectURI(target string, ids ...string) (string, error) {
+	if strings.HasPrefix(target, "/") {
+		if len(ids) > 0 {
+			return "", errors.New("ids can not be used with a URI")
+		}
+		return target, nil
+	}
+
+	parsed, err := sh.parse(sh.cwd)
+	if err != nil {
+		return "", err
+	}
+	if parsed.IsNamespace() {
+		return "", fmt.Errorf("'%s' is not a model, cd to a model to use ids", sh.cwd)
+	}
+
+	return parsed.WithIds(append([]string{target}, ids...)...).Build()
+}
+
+func (sh *shell) cd(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return errors.New("too many arguments")
+	}
+
+	target := "/"
+	if len(args) == 1 {
+		target = args[0]
+	}
+
+	uri, err := sh.resolve(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	_, describeType, err := sh.describe(ctx, uri)
+	if err != nil {
+		return err
+	}
+	if describeType != "Namespace" && describeType != "Model" {
+		return fmt.Errorf("'%s' is a '%s', not a namespace or model", uri, describeType)
+	}
+
+	sh.cwd = uri
+
+	return nil
+}
+
+func (sh *shell) ls(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	flags.SetOutput(sh.out)
+	filterName := flags.String("filter", "", "list filter")
+	position := flags.Int("position", 0, "position to list from")
+	count := flags.Int("count", 50, "number of objects to list")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	parsed, err := sh.parse(sh.cwd)
+	if err != nil {
+		return err
+	}
+
+	if parsed.IsNamespace() {
+		describe, _, err := sh.describe(ctx, sh.cwd)
+		if err != nil {
+			return err
+		}
+		t := &table{header: []string{"KIND", "NAME"}}
+		for _, item := range describe.Namespaces {
+			t.add("namespace", name(item)+"/")
+		}
+		for _, item := range describe.Models {
+			t.add("model", name(item))
+		}
+		return t.write(sh.out)
+	}
+
+	filter, err := sh.client.NewListFilter(ctx, sh.cwd, *filterName)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]cinp.FieldParamater{}
+	if *filterName != "" {
+		describe, _, err := sh.describe(ctx, sh.cwd)
+		if err != nil {
+			return err
+		}
+		for _, field := range describe.ListFilters[*filterName] {
+			fields[field.Name] = field
+		}
+	}
+
+	for _, arg := range flags.Args() {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("filter value '%s' is not in the form FIELD=VALUE", arg)
+		}
+		if err := filter.Set(name, paramaterValue(fields[name], value)); err != nil {
+			return err
+		}
+	}
+
+	uriList, listPosition, listCount, total, err := filter.List(ctx, *position, *count)
+	if err != nil {
+		return err
+	}
+
+	ids, err := sh.uri.ExtractIds(uriList)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		fmt.Fprintln(sh.out, id)
+	}
+	fmt.Fprintf(sh.out, "(%d-%d of %d)\n", listPosition, listPosition+listCount, total)
+
+	return nil
+}
+
+func (sh *shell) describeCmd(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return errors.New("too many arguments")
+	}
+
+	target := ""
+	if len(args) == 1 {
+		target = args[0]
+	}
+
+	uri, err := sh.actionURI(ctx, target)
+	if err != nil {
+		if uri, err = sh.resolve(ctx, target); err != nil {
+			return err
+		}
+	}
+
+	describe, describeType, err := sh.describe(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(sh.out, describeOutput{Type: describeType, Describe: describe}, func() *table {
+		return describeTable(describeType, describe)
+	})
+}
+
+func (sh *shell) get(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	flags.SetOutput(sh.out)
+	fields := flags.String("fields", "", "comma separated fields to show")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) < 1 {
+		return errors.New("a ID or URI is required")
+	}
+
+	fieldList := []string{}
+	if *fields != "" {
+		fieldList = strings.Split(*fields, ",")
+	}
+
+	uri, err := sh.objectURI(args[0], args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := sh.parse(uri)
+	if err != nil {
+		return err
+	}
+
+	if parsed.IsMulti {
+		objects, err := sh.client.GetMulti(ctx, uri)
+		if err != nil {
+			return err
+		}
+		return sh.writeObjects(objects, fieldList...)
+	}
+
+	object, err := sh.client.Get(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	return sh.writeObjects(map[string]cinp.Object{uri: *object}, fieldList...)
+}
+
+// writeObjects writes the objects, with only the fields in fieldList if there are any
+func (sh *shell) writeObjects(objects map[string]cinp.Object, fieldList ...string) error {
+	result, err := objectValues(objects)
+	if err != nil {
+		return err
+	}
+
+	if len(fieldList) > 0 {
+		for uri, values := range result {
+			selected := map[string]interface{}{}
+			for _, field := range fieldList {
+				if value, ok := values[field]; ok {
+					selected[field] = value
+				}
+			}
+			result[uri] = selected
+		}
+	}
+
+	return writeOutput(sh.out, result, func() *table { return objectTable(result) })
+}
+
+// actionURI is the URI of the action, action is a action of the current model or a action URI
+func (sh *shell) actionURI(ctx context.Context, action string) (string, error) {
+	if strings.HasPrefix(action, "/") {
+		parsed, err := sh.parse(action)
+		if err != nil {
+			return "", err
+		}
+		if parsed.Action == "" {
+			return "", fmt.Errorf("'%s' is not an action", action)
+		}
+		return action, nil
+	}
+
+	parsed, err := sh.parse(sh.cwd)
+	if err != nil {
+		return "", err
+	}
+	if parsed.IsNamespace() {
+		return "", fmt.Errorf("'%s' is not a model, cd to a model to use it's actions", sh.cwd)
+	}
+
+	describe, _, err := sh.describe(ctx, sh.cwd)
+	if err != nil {
+		return "", err
+	}
+	for _, item := range describe.Actions {
+		if name(item) == action {
+			return item, nil
+		}
+	}
+
+	return "", fmt.Errorf("action '%s' not found in '%s'", action, sh.cwd)
+}
+
+// paramaterValue is value as is for String paramaters, so ie "123" stays a string, otherwise it is parsed
+// with parseScalar
+func paramaterValue(paramater cinp.FieldParamater, value string) interface{} {
+	if paramater.Type == "String" && !paramater.IsArray {
+		return value
+	}
+
+	return parseScalar(value)
+}
+
+// promptParamaters asks for the value of each of the paramaters, blank skips a paramater that is not required
+func (sh *shell) promptParamaters(paramaterList []cinp.FieldParamater) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for _, paramater := range paramaterList {
+		prompt := fmt.Sprintf("%s (%s", paramater.Name, fieldType(paramater))
+		if paramater.Required {
+			prompt += ", required"
+		}
+		if paramater.Default != nil {
+			prompt += fmt.Sprintf(", default %s", cellString(paramater.Default))
+		}
+		prompt += "): "
+
+		for {
+			line, err := sh.input(prompt)
+			if err != nil {
+				return nil, err
+			}
+
+			line = strings.TrimSpace(line)
+			if line == "" {
+				if paramater.Required {
+					continue
+				}
+				break
+			}
+
+			result[paramater.Name] = paramaterValue(paramater, line)
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (sh *shell) call(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return errors.New("a ACTION or URI is required")
+	}
+
+	uri, err := sh.actionURI(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 1 {
+		parsed, err := sh.parse(uri)
+		if err != nil {
+			return err
+		}
+		if uri, err = parsed.WithIds(args[1:]...).Build(); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := sh.parse(uri)
+	if err != nil {
+		return err
+	}
+
+	describe, _, err := sh.describe(ctx, parsed.WithIds().String())
+	if err != nil {
+		return err
+	}
+
+	values, err := sh.promptParamaters(describe.Paramaters)
+	if err != nil {
+		return err
+	}
+
+	if parsed.IsMulti {
+		results, err := cinp.CallMultiAs[interface{}](ctx, sh.client, uri, values)
+		if err != nil {
+			return err
+		}
+
+		output := map[string]interface{}{}
+		for objectURI, item := range results {
+			output[objectURI] = item.Value
+			if item.Err != nil {
+				output[objectURI] = item.Err.Error()
+			}
+		}
+		return writeOutput(sh.out, output, func() *table { return valueTable(output) })
+	}
+
+	var result interface{}
+	if err := sh.client.CallAction(ctx, uri, values, &result); err != nil {
+		return err
+	}
+
+	return writeOutput(sh.out, result, func() *table { return valueTable(result) })
+}
+
+// editor is $VISUAL, $EDITOR or vi
+func editor() string {
+	return firstSet(os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi")
+}
+
+// changedValues returns the values in after that are not the same as in before, compared as JSON
+func changedValues(before map[string]interface{}, after map[string]interface{}) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for key, value := range after {
+		old, ok := before[key]
+		if ok {
+			oldJSON, err := json.Marshal(old)
+			if err != nil {
+				return nil, err
+			}
+			newJSON, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			var a, b interface{}
+			if err := json.Unmarshal(oldJSON, &a); err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(newJSON, &b); err != nil {
+				return nil, err
+			}
+			if reflect.DeepEqual(a, b) {
+				continue
+			}
+		}
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func (sh *shell) edit(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("one ID or URI is required")
+	}
+
+	uri, err := sh.objectURI(args[0])
+	if err != nil {
+		return err
+	}
+
+	object, err := sh.client.Get(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	mo, err := cinp.NewMappedObject(*object)
+	if err != nil {
+		return err
+	}
+
+	buff := &bytes.Buffer{}
+	fmt.Fprintf(buff, "# %s\n", uri)
+	if err := cli.WriteYAML(buff, mo.Data); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "cinp-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(buff.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", editor()+` "$1"`, "sh", file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return err
+	}
+
+	values, err := parseValues(edited)
+	if err != nil {
+		return err
+	}
+
+	changed, err := changedValues(mo.Data, values)
+	if err != nil {
+		return err
+	}
+
+	if len(changed) == 0 {
+		fmt.Fprintln(sh.out, "no changes")
+		return nil
+	}
+
+	update := &cinp.MappedObject{Data: changed}
+	update.SetURI(uri)
+	result, err := sh.client.Update(ctx, update)
+	if err != nil {
+		return err
+	}
+
+	return sh.writeObjects(map[string]cinp.Object{uri: *result})
+}
+
+func (sh *shell) help(ctx context.Context, args []string) error {
+	names := []string{}
+	for name := range replCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(sh.out, "  %s\n", replCommands[name].usage)
+	}
+	fmt.Fprintf(sh.out, "\nPATHs are relative to the current namespace or model, IDs are of the current model.\n")
+
+	return nil
+}
+
+// parseScalar parses a value typed at the shell as YAML, so numbers, booleans and lists have their type.  A
+// value that does not parse is the string.
+func parseScalar(value string) interface{} {
+	var result interface{}
+	if err := yaml.Unmarshal([]byte(value), &result); err != nil || result == nil {
+		return value
+	}
+
+	return normalize(result)
+}
+
+// splitArgs splits line on spaces, double and single quotes keep spaces in a argument
+func splitArgs(line string) ([]string, error) {
+	result := []string{}
+	current := strings.Builder{}
+	inWord := false
+	var quote rune
+	for _, char := range line {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+
+		case char == '"' || char == '\'':
+			quote = char
+			inWord = true
+
+		case char == ' ' || char == '\t':
+			if inWord {
+				result = append(result, current.String())
+				current.Reset()
+				inWord = false
+			}
+
+		default:
+			current.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		result = append(result, current.String())
+	}
+
+	return result, nil
+}
+
+// run runs one line, returns false when the shell should exit
+func (sh *shell) run(ctx context.Context, line string) (bool, error) {
+	args, err := splitArgs(line)
+	if err != nil {
+		return true, err
+	}
+	if len(args) == 0 {
+		return true, nil
+	}
+
+	if args[0] == "exit" || args[0] == "quit" {
+		return false, nil
+	}
+
+	cmd, ok := replCommands[args[0]]
+	if !ok {
+		return true, fmt.Errorf("unknown command '%s', try help", args[0])
+	}
+
+	return true, cmd.run(sh, ctx, args[1:])
+}
+
+// historyPath is $XDG_CACHE_HOME/cinp/history or the OS equivalent, "" if there is no cache dir
+func historyPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	dir = filepath.Join(dir, "cinp")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "history")
+}
+
+func shellCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("shell", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.EnableDescribeCache(0, ""); err != nil {
+		return err
+	}
+
+	sh := &shell{client: client, uri: client.GetURI(), describe: client.Describe, cwd: rootPath}
+
+	rl, err := readline.NewEx(&readline.Config{
+		HistoryFile:     historyPath(),
+		AutoComplete:    &completer{sh: sh, ctx: ctx},
+		InterruptPrompt: "^C",
+		EOFPrompt:       "exit",
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+
+	sh.out = rl.Stdout()
+	sh.input = func(prompt string) (string, error) {
+		rl.SetPrompt(prompt)
+		rl.Config.DisableAutoSaveHistory = true
+		defer func() { rl.Config.DisableAutoSaveHistory = false }()
+		return rl.Readline()
+	}
+
+	if flags.NArg() > 0 {
+		if err := sh.cd(ctx, flags.Args()[:1]); err != nil {
+			return err
+		}
+	}
+
+	for {
+		rl.SetPrompt(sh.cwd + "> ")
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		more, err := sh.run(ctx, line)
+		if err != nil {
+			fmt.Fprintf(rl.Stderr(), "error: %s\n", err)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	cinp "github.com/cinp/go"
+)
+
+var testDescribes = map[string]struct {
+	describeType string
+	describe     cinp.Describe
+}{
+	"/api/v1/":              {"Namespace", cinp.Describe{Namespaces: []string{"/api/v1/Building/"}, Models: []string{"/api/v1/User"}}},
+	"/api/v1/Building/":     {"Namespace", cinp.Describe{Models: []string{"/api/v1/Building/Site", "/api/v1/Building/Room"}}},
+	"/api/v1/User":          {"Model", cinp.Describe{}},
+	"/api/v1/Building/Room": {"Model", cinp.Describe{}},
+	"/api/v1/Building/Site": {"Model", cinp.Describe{
+		Fields:      []cinp.FieldParamater{{Name: "name", Type: "String"}, {Name: "size", Type: "Integer"}},
+		Actions:     []string{"/api/v1/Building/Site(move)", "/api/v1/Building/Site(ping)"},
+		ListFilters: map[string][]cinp.FieldParamater{"name": {{Name: "name", Type: "String"}}},
+	}},
+	"/api/v1/Building/Site(move)": {"Action", cinp.Describe{Paramaters: []cinp.FieldParamater{{Name: "to", Type: "String", Required: true}, {Name: "count", Type: "Integer"}}, ReturnType: cinp.FieldParamater{Type: "Map"}}},
+}
+
+func testShell(t *testing.T) (*shell, *bytes.Buffer, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		switch req.Method {
+		case "DESCRIBE":
+			item, ok := testDescribes[req.URL.Path]
+			if !ok {
+				rw.WriteHeader(404)
+				return
+			}
+			rw.Header().Set("Type", item.describeType)
+			json.NewEncoder(rw).Encode(item.describe)
+
+		case "LIST":
+			rw.Header().Set("Position", "0")
+			rw.Header().Set("Count", "2")
+			rw.Header().Set("Total", "2")
+			json.NewEncoder(rw).Encode([]string{"/api/v1/Building/Site:1:", "/api/v1/Building/Site:2:"})
+
+		case "CALL", "UPDATE":
+			if req.Method == "CALL" && strings.HasPrefix(req.URL.Path, "/api/v1/Building/Site:1:2:") {
+				rw.Header().Set("Multi-Object", "True")
+				fmt.Fprintf(rw, `{"/api/v1/Building/Site:1:": %s, "/api/v1/Building/Site:2:": %s}`, body, body)
+				return
+			}
+			rw.Write(body) // the args/values as the result
+
+		case "GET":
+			rw.Write([]byte(`{"name": "one", "size": 4}`))
+		}
+	}))
+
+	client, err := cinp.NewCInP(slog.New(slog.NewTextHandler(io.Discard, nil)), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	out := &bytes.Buffer{}
+	format = "table"
+	sh := &shell{client: client, uri: client.GetURI(), describe: client.Describe, cwd: "/api/v1/", out: out}
+
+	return sh, out, server.Close
+}
+
+func TestShellCd(t *testing.T) {
+	sh, _, done := testShell(t)
+	defer done()
+	ctx := context.TODO()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"Building", "/api/v1/Building/"},
+		{"Site", "/api/v1/Building/Site"},
+		{"..", "/api/v1/Building/"},
+		{"../User", "/api/v1/User"},
+		{"/api/v1/Building/Room", "/api/v1/Building/Room"},
+		{"/", "/api/v1/"},
+		{"Building/Site", "/api/v1/Building/Site"},
+	}
+	for _, test := range tests {
+		if err := sh.cd(ctx, []string{test.path}); err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, test.path)
+			t.FailNow()
+		}
+		if sh.cwd != test.expected {
+			t.Errorf("Expected '%s' got '%s' for '%s'", test.expected, sh.cwd, test.path)
+			t.FailNow()
+		}
+	}
+
+	for _, path := range []string{"Nope", "name", "/api/v1/Building/Site(move)"} {
+		if err := sh.cd(ctx, []string{path}); err == nil {
+			t.Errorf("error missing for '%s'", path)
+			t.FailNow()
+		}
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	sh, _, done := testShell(t)
+	defer done()
+	c := &completer{sh: sh, ctx: context.TODO()}
+
+	complete := func(line string) []string {
+		candidates, _ := c.Do([]rune(line), len([]rune(line)))
+		result := []string{}
+		for _, item := range candidates {
+			result = append(result, string(item))
+		}
+		return result
+	}
+
+	tests := []struct {
+		cwd      string
+		line     string
+		expected []string
+	}{
+		{"/api/v1/", "e", []string{"dit", "xit"}},
+		{"/api/v1/", "cd ", []string{"../", "Building/", "User"}},
+		{"/api/v1/", "cd B", []string{"uilding/"}},
+		{"/api/v1/", "cd Building/S", []string{"ite"}},
+		{"/api/v1/Building/Site", "call ", []string{"move", "ping"}},
+		{"/api/v1/Building/Site", "describe m", []string{"ove"}},
+		{"/api/v1/Building/Site", "ls -filter ", []string{"name"}},
+		{"/api/v1/Building/Site", "ls -filter name n", []string{"ame="}},
+		{"/api/v1/Building/Site", "get -fields name,s", []string{"ize"}},
+		{"/api/v1/", "call ", []string{}},
+	}
+	for _, test := range tests {
+		sh.cwd = test.cwd
+		if result := complete(test.line); !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Expected '%v' got '%v' for '%s'", test.expected, result, test.line)
+			t.FailNow()
+		}
+	}
+}
+
+func TestShellLsAndCall(t *testing.T) {
+	sh, out, done := testShell(t)
+	defer done()
+	ctx := context.TODO()
+
+	if _, err := sh.run(ctx, "ls"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !strings.Contains(out.String(), "namespace  Building/") || !strings.Contains(out.String(), "model      User") {
+		t.Errorf("Wrong ls '%s'", out.String())
+		t.FailNow()
+	}
+
+	sh.cwd = "/api/v1/Building/Site"
+	out.Reset()
+	if _, err := sh.run(ctx, "ls -filter name name='main site'"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if out.String() != "1\n2\n(0-2 of 2)\n" {
+		t.Errorf("Wrong ls '%s'", out.String())
+		t.FailNow()
+	}
+
+	// a number is still a string for a String filter field
+	out.Reset()
+	if _, err := sh.run(ctx, "ls -filter name name=123"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	answers := []string{"", "up", "3"} // the blank answer for the required paramater is asked again
+	prompts := []string{}
+	sh.input = func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+
+	out.Reset()
+	if _, err := sh.run(ctx, "call move 1"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(prompts, []string{"to (String, required): ", "to (String, required): ", "count (Integer): "}) {
+		t.Errorf("Wrong prompts '%v'", prompts)
+		t.FailNow()
+	}
+	if out.String() != "KEY    VALUE\ncount  3\nto     up\n" {
+		t.Errorf("Wrong result '%s'", out.String())
+		t.FailNow()
+	}
+
+	// a URI with more than one id is a multi call
+	answers = []string{"down", ""}
+	out.Reset()
+	if _, err := sh.run(ctx, "call /api/v1/Building/Site:1:2:(move)"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if out.String() != "KEY                       VALUE\n/api/v1/Building/Site:1:  {\"to\":\"down\"}\n/api/v1/Building/Site:2:  {\"to\":\"down\"}\n" {
+		t.Errorf("Wrong result '%s'", out.String())
+		t.FailNow()
+	}
+
+	if more, err := sh.run(ctx, "exit"); more || err != nil {
+		t.Errorf("Expected exit got '%v' '%v'", more, err)
+		t.FailNow()
+	}
+	if _, err := sh.run(ctx, "bogus"); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		"":                      {},
+		"ls  -filter name":      {"ls", "-filter", "name"},
+		`ls name="main site" x`: {"ls", "name=main site", "x"},
+		`get 'a b' ""`:          {"get", "a b", ""},
+	}
+	for line, expected := range tests {
+		result, err := splitArgs(line)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Expected '%v' got '%v' for '%s'", expected, result, line)
+			t.FailNow()
+		}
+	}
+
+	if _, err := splitArgs(`ls "open`); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestChangedValues(t *testing.T) {
+	before := map[string]interface{}{"name": "a", "size": json.Number("4"), "tags": []interface{}{"x"}}
+	after := map[string]interface{}{"name": "a", "size": 5, "tags": []interface{}{"x"}, "new": true}
+
+	result, err := changedValues(before, after)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(result, map[string]interface{}{"size": 5, "new": true}) {
+		t.Errorf("Wrong changes '%v'", result)
+		t.FailNow()
+	}
+}
+
+func TestShellEdit(t *testing.T) {
+	sh, out, done := testShell(t)
+	defer done()
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "sed -i -e s/one/two/")
+
+	sh.cwd = "/api/v1/Building/Site"
+	if _, err := sh.run(context.TODO(), "edit 1"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if out.String() != "URI                       name\n/api/v1/Building/Site:1:  two\n" {
+		t.Errorf("Wrong result '%s'", out.String())
+		t.FailNow()
+	}
+
+	t.Setenv("EDITOR", "true")
+	out.Reset()
+	if _, err := sh.run(context.TODO(), "edit 1"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if out.String() != "no changes\n" {
+		t.Errorf("Wrong result '%s'", out.String())
+		t.FailNow()
+	}
+}
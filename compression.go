@@ -0,0 +1,117 @@
+package cinp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported Content-Encodings for request and response bodies
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingZstd    = "zstd"
+)
+
+func checkEncoding(encoding string) error {
+	switch encoding {
+	case EncodingGzip, EncodingDeflate, EncodingZstd:
+		return nil
+	}
+	return fmt.Errorf("unsupported encoding '%s'", encoding)
+}
+
+// SetRequestCompression sets the encoding used to compress request bodies, "" disables request compression.
+// NOTE: the server must support decoding the request body, there is no negotiation for requests
+func (cinp *CInP) SetRequestCompression(encoding string) error {
+	if encoding != "" {
+		if err := checkEncoding(encoding); err != nil {
+			return err
+		}
+	}
+
+	cinp.log.Debug("Set Request Compression", "encoding", encoding)
+	cinp.requestEncoding = encoding
+
+	return nil
+}
+
+// SetAcceptEncoding sets the encodings sent in the Accept-Encoding header, in order of preference.
+// With no encodings, the go http client's default (transparent gzip) is used.
+func (cinp *CInP) SetAcceptEncoding(encodings ...string) error {
+	for _, encoding := range encodings {
+		if err := checkEncoding(encoding); err != nil {
+			return err
+		}
+	}
+
+	cinp.log.Debug("Set Accept Encoding", "encodings", encodings)
+	cinp.acceptEncodings = encodings
+
+	return nil
+}
+
+func compressBody(encoding string, body []byte) ([]byte, error) {
+	var writer io.WriteCloser
+	var err error
+	buffer := &bytes.Buffer{}
+
+	switch encoding {
+	case EncodingGzip:
+		writer = gzip.NewWriter(buffer)
+	case EncodingDeflate: // HTTP's "deflate" is the zlib format, see RFC 9110 section 8.4.1.2
+		writer = zlib.NewWriter(buffer)
+	case EncodingZstd:
+		writer, err = zstd.NewWriter(buffer)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding '%s'", encoding)
+	}
+
+	if _, err = writer.Write(body); err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// decompressBody wraps the response body with a reader for the Content-Encoding.  If the go http client
+// transparently decompressed the body, the Content-Encoding header has already been removed.
+func decompressBody(encoding string, body io.Reader) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return io.NopCloser(body), nil
+	case EncodingGzip:
+		reader, err := gzip.NewReader(body)
+		if err == io.EOF { // empty body, nothing to decompress
+			return io.NopCloser(body), nil
+		}
+		return reader, err
+	case EncodingDeflate:
+		reader, err := zlib.NewReader(body)
+		if err == io.EOF {
+			return io.NopCloser(body), nil
+		}
+		return reader, err
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported response encoding '%s'", encoding)
+}
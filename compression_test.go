@@ -0,0 +1,131 @@
+package cinp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCompressionSettings(t *testing.T) {
+	c, err := NewCInP(getLogger(), "http://host", "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	for _, v := range []string{"", EncodingGzip, EncodingDeflate, EncodingZstd} {
+		if err := c.SetRequestCompression(v); err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, v)
+			t.FailNow()
+		}
+	}
+
+	if err := c.SetRequestCompression("br"); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	if err := c.SetAcceptEncoding(EncodingZstd, EncodingGzip, EncodingDeflate); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := c.SetAcceptEncoding(EncodingGzip, "compress"); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	var reqEncoding string
+	var reqAcceptEncoding string
+	var reqData []byte
+	var respEncoding string
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		reqEncoding = req.Header.Get("Content-Encoding")
+		reqAcceptEncoding = req.Header.Get("Accept-Encoding")
+		reader, err := decompressBody(reqEncoding, req.Body)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			return
+		}
+		reqData, _ = io.ReadAll(reader)
+
+		body := []byte("{\"a\": \"bob\"}")
+		if respEncoding != "" {
+			if compressed, err := compressBody(respEncoding, body); err == nil { // unsupported encodings are sent as is
+				body = compressed
+			}
+			rw.Header().Set("Content-Encoding", respEncoding)
+		}
+		rw.Write(body)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	for _, encoding := range []string{EncodingGzip, EncodingDeflate, EncodingZstd} {
+		if err := c.SetRequestCompression(encoding); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if err := c.SetAcceptEncoding(encoding); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		respEncoding = encoding
+
+		respDataOut := map[string]interface{}{}
+		_, _, err = c.request(context.TODO(), "CALL", "/api/v1/ns/model(action)", &map[string]interface{}{"stuff": "jane"}, &respDataOut, nil)
+		if err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, encoding)
+			t.FailNow()
+		}
+		if reqEncoding != encoding {
+			t.Errorf("Expected Content-Encoding '%s' got '%s'", encoding, reqEncoding)
+			t.FailNow()
+		}
+		if reqAcceptEncoding != encoding {
+			t.Errorf("Expected Accept-Encoding '%s' got '%s'", encoding, reqAcceptEncoding)
+			t.FailNow()
+		}
+		cmp := []byte("{\"stuff\":\"jane\"}\n")
+		if !bytes.Equal(reqData, cmp) {
+			t.Errorf("got wrong data body, got '%s' exptected '%s'", reqData, cmp)
+			t.FailNow()
+		}
+		if !reflect.DeepEqual(respDataOut, map[string]interface{}{"a": "bob"}) {
+			t.Errorf("returned result wrong, got '%s' for '%s'", respDataOut, encoding)
+			t.FailNow()
+		}
+	}
+
+	// no body, no Content-Encoding
+	_, _, err = c.request(context.TODO(), "GET", "/api/v1/ns/model:1:", nil, nil, nil)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if reqEncoding != "" {
+		t.Errorf("Expected no Content-Encoding got '%s'", reqEncoding)
+		t.FailNow()
+	}
+
+	respEncoding = "br"
+	_, _, err = c.request(context.TODO(), "GET", "/api/v1/ns/model:1:", nil, nil, nil)
+	if err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
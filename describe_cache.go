@@ -0,0 +1,262 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// how often the root namespace's APIVersion is checked again while the describe cache is used
+const describeCacheCheckInterval = 5 * time.Minute
+
+type describeCacheEntry struct {
+	Describe *Describe `json:"describe"`
+	Type     string    `json:"type"`
+	Expires  time.Time `json:"expires"` // zero value for never
+}
+
+// the on disk format of the describe cache
+type describeCacheFile struct {
+	Host       string                        `json:"host"`
+	APIVersion string                        `json:"api-version"`
+	Entries    map[string]describeCacheEntry `json:"entries"`
+}
+
+type describeCache struct {
+	mutex      sync.Mutex
+	ttl        time.Duration
+	path       string
+	host       string
+	apiVersion string
+	checked    time.Time     // when the root namespace's APIVersion was last checked against the server, zero for not yet
+	interval   time.Duration // how often to check the APIVersion again, 0 for never
+	entries    map[string]describeCacheEntry
+}
+
+func (c *describeCache) get(uri string) (*Describe, string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[uri]
+	if !ok {
+		return nil, "", false
+	}
+
+	if !entry.Expires.IsZero() && time.Now().After(entry.Expires) {
+		delete(c.entries, uri)
+		return nil, "", false
+	}
+
+	return entry.Describe, entry.Type, true
+}
+
+func (c *describeCache) set(uri string, describe *Describe, describeType string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.setLocked(uri, describe, describeType)
+}
+
+func (c *describeCache) setLocked(uri string, describe *Describe, describeType string) {
+	entry := describeCacheEntry{Describe: describe, Type: describeType}
+	if c.ttl > 0 {
+		entry.Expires = time.Now().Add(c.ttl)
+	}
+
+	c.entries[uri] = entry
+}
+
+// setRoot stores the describe of the root namespace, if the APIVersion has changed everything else is flushed
+func (c *describeCache) setRoot(uri string, describe *Describe, describeType string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	changed := c.apiVersion != "" && c.apiVersion != describe.APIVersion
+	if changed {
+		c.entries = map[string]describeCacheEntry{}
+	}
+
+	c.apiVersion = describe.APIVersion
+	c.checked = time.Now()
+	c.setLocked(uri, describe, describeType)
+
+	return changed
+}
+
+func (c *describeCache) load() error {
+	buff, err := os.ReadFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	file := describeCacheFile{}
+	if err := json.Unmarshal(buff, &file); err != nil {
+		return err
+	}
+
+	if file.Host != c.host { // cached from some other server, start over
+		return nil
+	}
+
+	now := time.Now()
+	for uri, entry := range file.Entries {
+		if entry.Describe == nil || (!entry.Expires.IsZero() && now.After(entry.Expires)) {
+			continue
+		}
+		c.entries[uri] = entry
+	}
+	c.apiVersion = file.APIVersion
+
+	return nil
+}
+
+func (c *describeCache) save() error {
+	c.mutex.Lock()
+	buff, err := json.Marshal(describeCacheFile{Host: c.host, APIVersion: c.apiVersion, Entries: c.entries})
+	c.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".describe-cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buff); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path) // rename so concurrent runs never see a partial file
+}
+
+// EnableDescribeCache caches the results of Describe by URI for ttl, a ttl of 0 caches until invalidated.
+// If path is not "", the cache is loaded from path and SaveDescribeCache will write it back. The whole cache
+// is invalidated when the APIVersion of the root namespace changes, this is checked the first time
+// the cache is used, every 5 minutes after that (see SetDescribeCacheCheckInterval) and every time the root
+// namespace is described.
+func (cinp *CInP) EnableDescribeCache(ttl time.Duration, path string) error {
+	cache := &describeCache{
+		ttl:      ttl,
+		path:     path,
+		host:     cinp.host,
+		interval: describeCacheCheckInterval,
+		entries:  map[string]describeCacheEntry{},
+	}
+
+	if path != "" {
+		if err := cache.load(); err != nil {
+			return err
+		}
+	}
+	if len(cache.entries) == 0 { // nothing cached yet, so nothing to check
+		cache.checked = time.Now()
+	}
+
+	cinp.log.Debug("Enable Describe Cache", "ttl", ttl, "path", path, "entries", len(cache.entries))
+	cinp.describeCache = cache
+
+	return nil
+}
+
+// SetDescribeCacheCheckInterval sets how often the root namespace's APIVersion is checked again, 0 turns the
+// periodic check off
+func (cinp *CInP) SetDescribeCacheCheckInterval(interval time.Duration) error {
+	if cinp.describeCache == nil {
+		return errors.New("describe cache is not enabled")
+	}
+
+	cinp.describeCache.mutex.Lock()
+	defer cinp.describeCache.mutex.Unlock()
+
+	cinp.describeCache.interval = interval
+
+	return nil
+}
+
+// DisableDescribeCache stops caching Describe results and drops the cache, the file on disk is left as is
+func (cinp *CInP) DisableDescribeCache() {
+	cinp.log.Debug("Disable Describe Cache")
+	cinp.describeCache = nil
+}
+
+// InvalidateDescribe removes the cached describe for the uri
+func (cinp *CInP) InvalidateDescribe(uri string) {
+	if cinp.describeCache == nil {
+		return
+	}
+
+	cinp.log.Debug("Invalidate Describe", "uri", uri)
+	cinp.describeCache.mutex.Lock()
+	defer cinp.describeCache.mutex.Unlock()
+
+	delete(cinp.describeCache.entries, uri)
+}
+
+// InvalidateDescribeCache removes all the cached describes
+func (cinp *CInP) InvalidateDescribeCache() {
+	if cinp.describeCache == nil {
+		return
+	}
+
+	cinp.log.Debug("Invalidate Describe Cache")
+	cinp.describeCache.mutex.Lock()
+	defer cinp.describeCache.mutex.Unlock()
+
+	cinp.describeCache.entries = map[string]describeCacheEntry{}
+}
+
+// SaveDescribeCache writes the describe cache to the path passed to EnableDescribeCache
+func (cinp *CInP) SaveDescribeCache() error {
+	if cinp.describeCache == nil {
+		return errors.New("describe cache is not enabled")
+	}
+
+	if cinp.describeCache.path == "" {
+		return errors.New("describe cache does not have a path")
+	}
+
+	return cinp.describeCache.save()
+}
+
+// checkDescribeCache makes sure the cache is still for the server's APIVersion, the root namespace is described
+// again if it has not been checked yet or the check interval has passed
+func (cinp *CInP) checkDescribeCache(ctx context.Context, cache *describeCache) error {
+	cache.mutex.Lock()
+	due := cache.checked.IsZero() || (cache.interval > 0 && time.Since(cache.checked) >= cache.interval)
+	cache.mutex.Unlock()
+	if !due {
+		return nil
+	}
+
+	rootPath := cinp.uri.rootPath
+	result, describeType, err := cinp.describe(ctx, rootPath)
+	if err != nil {
+		return err
+	}
+
+	if cache.setRoot(rootPath, result, describeType) {
+		cinp.log.Info("API Version changed, describe cache invalidated", "version", result.APIVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDescribeCache(t *testing.T) {
+	apiVersion := "1.0"
+	requestCount := map[string]int{}
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestCount[req.URL.Path]++
+		result := map[string]interface{}{"name": "root", "path": req.URL.Path}
+		if req.URL.Path == "/api/v1/" {
+			result["api-version"] = apiVersion
+			rw.Header().Set("Type", "Namespace")
+		} else {
+			rw.Header().Set("Type", "Model")
+		}
+		json.NewEncoder(rw).Encode(result)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	cachePath := filepath.Join(t.TempDir(), "cache", "describe.json")
+	if err := c.EnableDescribeCache(0, cachePath); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	for i := 0; i < 3; i++ {
+		describe, describeType, err := c.Describe(context.TODO(), "/api/v1/ns/model")
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if describeType != "Model" || describe.Path != "/api/v1/ns/model" {
+			t.Errorf("Expected 'Model', '/api/v1/ns/model' got '%s', '%s'", describeType, describe.Path)
+			t.FailNow()
+		}
+	}
+	if requestCount["/api/v1/ns/model"] != 1 {
+		t.Errorf("Expected 1 request got %d", requestCount["/api/v1/ns/model"])
+		t.FailNow()
+	}
+
+	c.InvalidateDescribe("/api/v1/ns/model")
+	if _, _, err = c.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if requestCount["/api/v1/ns/model"] != 2 {
+		t.Errorf("Expected 2 requests got %d", requestCount["/api/v1/ns/model"])
+		t.FailNow()
+	}
+
+	if _, _, err = c.Describe(context.TODO(), "/api/v1/"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := c.SaveDescribeCache(); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	// a new "run" loads from disk, and checks the root namespace once
+	c2, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if err := c2.EnableDescribeCache(0, cachePath); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	for i := 0; i < 3; i++ {
+		if _, _, err = c2.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+	}
+	if requestCount["/api/v1/ns/model"] != 2 || requestCount["/api/v1/"] != 2 {
+		t.Errorf("Expected 2 and 2 requests got %d and %d", requestCount["/api/v1/ns/model"], requestCount["/api/v1/"])
+		t.FailNow()
+	}
+
+	// the APIVersion changed, everything cached is invalidated
+	apiVersion = "2.0"
+	c3, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if err := c3.EnableDescribeCache(0, cachePath); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, _, err = c3.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if requestCount["/api/v1/ns/model"] != 3 {
+		t.Errorf("Expected 3 requests got %d", requestCount["/api/v1/ns/model"])
+		t.FailNow()
+	}
+
+	// and with out reloading
+	apiVersion = "3.0"
+	c3.InvalidateDescribe("/api/v1/")
+	if _, _, err = c3.Describe(context.TODO(), "/api/v1/"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, _, err = c3.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if requestCount["/api/v1/ns/model"] != 4 {
+		t.Errorf("Expected 4 requests got %d", requestCount["/api/v1/ns/model"])
+		t.FailNow()
+	}
+
+	c3.InvalidateDescribeCache()
+	c3.DisableDescribeCache()
+	if err := c3.SaveDescribeCache(); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestDescribeCacheTTL(t *testing.T) {
+	requestCount := 0
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestCount++
+		rw.Header().Set("Type", "Model")
+		rw.Write([]byte("{\"name\": \"model\"}"))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := c.EnableDescribeCache(time.Millisecond*50, ""); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err = c.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+	}
+	if requestCount != 1 {
+		t.Errorf("Expected 1 request got %d", requestCount)
+		t.FailNow()
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	if _, _, err = c.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if requestCount != 2 {
+		t.Errorf("Expected 2 requests got %d", requestCount)
+		t.FailNow()
+	}
+
+	if err := c.SaveDescribeCache(); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestDescribeCacheVersionCheck(t *testing.T) {
+	apiVersion := "1.0"
+	requestCount := map[string]int{}
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestCount[req.URL.Path]++
+		result := map[string]interface{}{"name": "root", "path": req.URL.Path}
+		if req.URL.Path == "/api/v1/" {
+			result["api-version"] = apiVersion
+			rw.Header().Set("Type", "Namespace")
+		} else {
+			rw.Header().Set("Type", "Model")
+		}
+		json.NewEncoder(rw).Encode(result)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := c.SetDescribeCacheCheckInterval(time.Millisecond * 50); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	if err := c.EnableDescribeCache(0, ""); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if err := c.SetDescribeCacheCheckInterval(time.Millisecond * 50); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	for _, uri := range []string{"/api/v1/", "/api/v1/ns/model", "/api/v1/ns/model"} {
+		if _, _, err = c.Describe(context.TODO(), uri); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+	}
+	if requestCount["/api/v1/ns/model"] != 1 || requestCount["/api/v1/"] != 1 {
+		t.Errorf("Expected 1 and 1 requests got %d and %d", requestCount["/api/v1/ns/model"], requestCount["/api/v1/"])
+		t.FailNow()
+	}
+
+	// the version is checked again after the interval, nothing has changed so the cache is kept
+	time.Sleep(time.Millisecond * 60)
+	if _, _, err = c.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if requestCount["/api/v1/ns/model"] != 1 || requestCount["/api/v1/"] != 2 {
+		t.Errorf("Expected 1 and 2 requests got %d and %d", requestCount["/api/v1/ns/model"], requestCount["/api/v1/"])
+		t.FailNow()
+	}
+
+	// the server's version changes mid session, the cache is invalidated after the interval
+	apiVersion = "2.0"
+	if _, _, err = c.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if requestCount["/api/v1/ns/model"] != 1 {
+		t.Errorf("Expected 1 request got %d", requestCount["/api/v1/ns/model"])
+		t.FailNow()
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	if _, _, err = c.Describe(context.TODO(), "/api/v1/ns/model"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if requestCount["/api/v1/ns/model"] != 2 || requestCount["/api/v1/"] != 3 {
+		t.Errorf("Expected 2 and 3 requests got %d and %d", requestCount["/api/v1/ns/model"], requestCount["/api/v1/"])
+		t.FailNow()
+	}
+}
@@ -0,0 +1,301 @@
+package cinp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Elements of a schema a SchemaChange can be about
+const (
+	ElementNamespace   = "namespace"
+	ElementModel       = "model"
+	ElementField       = "field"
+	ElementFilter      = "filter"
+	ElementFilterField = "filter-field"
+	ElementAction      = "action"
+	ElementParamater   = "paramater"
+	ElementReturnType  = "return-type"
+	ElementConstant    = "constant"
+	ElementMethod      = "method"
+)
+
+// Kinds of SchemaChanges
+const (
+	ChangeAdded           = "added"
+	ChangeRemoved         = "removed"
+	ChangeTypeChanged     = "type-changed"
+	ChangeRequiredChanged = "required-changed"
+	ChangeModeChanged     = "mode-changed"
+	ChangeChoicesChanged  = "choices-changed"
+	ChangeLengthChanged   = "length-changed"
+	ChangeStaticChanged   = "static-changed"
+	ChangeValueChanged    = "value-changed"
+	ChangeVersionChanged  = "version-changed"
+)
+
+// SchemaChange is one difference between two schemas
+type SchemaChange struct {
+	Element  string      `json:"element"`
+	Change   string      `json:"change"`
+	Path     string      `json:"path"`           // path of the namespace, model or action
+	Name     string      `json:"name,omitempty"` // name of the field, paramater, filter, constant or method
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+	Breaking bool        `json:"breaking"`
+}
+
+func (c SchemaChange) String() string {
+	result := fmt.Sprintf("%s %s '%s'", c.Element, c.Change, c.Path)
+	if c.Name != "" {
+		result += fmt.Sprintf(" '%s'", c.Name)
+	}
+
+	if c.Old != nil || c.New != nil {
+		result += fmt.Sprintf(" %v -> %v", c.Old, c.New)
+	}
+
+	if c.Breaking {
+		return "BREAKING: " + result
+	}
+
+	return result
+}
+
+// SchemaDiff is the list of differences between two schemas
+type SchemaDiff struct {
+	Changes []SchemaChange `json:"changes"`
+}
+
+// Breaking returns true if any of the changes are breaking
+func (d *SchemaDiff) Breaking() bool {
+	for _, change := range d.Changes {
+		if change.Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *SchemaDiff) add(element string, change string, path string, name string, before interface{}, after interface{}, breaking bool) {
+	d.Changes = append(d.Changes, SchemaChange{Element: element, Change: change, Path: path, Name: name, Old: before, New: after, Breaking: breaking})
+}
+
+// DiffSchema compares the schemas, changes that could break a client written against the before schema are flagged as Breaking
+func DiffSchema(before *Schema, after *Schema) *SchemaDiff {
+	result := &SchemaDiff{Changes: []SchemaChange{}}
+
+	oldNamespaces := map[string]*SchemaNamespace{}
+	before.Walk(func(namespace *SchemaNamespace) { oldNamespaces[namespace.Path] = namespace })
+	newNamespaces := map[string]*SchemaNamespace{}
+	after.Walk(func(namespace *SchemaNamespace) { newNamespaces[namespace.Path] = namespace })
+
+	for _, path := range sortedKeys(oldNamespaces, newNamespaces) {
+		oldNamespace, inOld := oldNamespaces[path]
+		newNamespace, inNew := newNamespaces[path]
+		switch {
+		case !inNew:
+			result.add(ElementNamespace, ChangeRemoved, path, "", nil, nil, true)
+		case !inOld:
+			result.add(ElementNamespace, ChangeAdded, path, "", nil, nil, false)
+		case oldNamespace.APIVersion != newNamespace.APIVersion:
+			result.add(ElementNamespace, ChangeVersionChanged, path, "", oldNamespace.APIVersion, newNamespace.APIVersion, false)
+		}
+	}
+
+	oldModels := map[string]*SchemaModel{}
+	for _, model := range before.Models() {
+		oldModels[model.Path] = model
+	}
+	newModels := map[string]*SchemaModel{}
+	for _, model := range after.Models() {
+		newModels[model.Path] = model
+	}
+
+	for _, path := range sortedKeys(oldModels, newModels) {
+		oldModel, inOld := oldModels[path]
+		newModel, inNew := newModels[path]
+		switch {
+		case !inNew:
+			result.add(ElementModel, ChangeRemoved, path, "", nil, nil, true)
+		case !inOld:
+			result.add(ElementModel, ChangeAdded, path, "", nil, nil, false)
+		default:
+			result.diffModel(oldModel, newModel)
+		}
+	}
+
+	return result
+}
+
+func (d *SchemaDiff) diffModel(before *SchemaModel, after *SchemaModel) {
+	d.diffFields(ElementField, before.Path, "", before.Fields, after.Fields)
+
+	for _, name := range sortedKeys(before.Constants, after.Constants) {
+		oldValue, inOld := before.Constants[name]
+		newValue, inNew := after.Constants[name]
+		switch {
+		case !inNew:
+			d.add(ElementConstant, ChangeRemoved, before.Path, name, nil, nil, true)
+		case !inOld:
+			d.add(ElementConstant, ChangeAdded, before.Path, name, nil, nil, false)
+		case oldValue != newValue:
+			d.add(ElementConstant, ChangeValueChanged, before.Path, name, oldValue, newValue, false)
+		}
+	}
+
+	oldMethods := map[string]bool{}
+	for _, method := range before.NotAllowedMethods {
+		oldMethods[method] = true
+	}
+	newMethods := map[string]bool{}
+	for _, method := range after.NotAllowedMethods {
+		newMethods[method] = true
+	}
+	for _, method := range sortedKeys(oldMethods, newMethods) {
+		switch {
+		case !oldMethods[method]: // newly not allowed
+			d.add(ElementMethod, ChangeRemoved, before.Path, method, nil, nil, true)
+		case !newMethods[method]:
+			d.add(ElementMethod, ChangeAdded, before.Path, method, nil, nil, false)
+		}
+	}
+
+	for _, name := range sortedKeys(before.ListFilters, after.ListFilters) {
+		oldFilter, inOld := before.ListFilters[name]
+		newFilter, inNew := after.ListFilters[name]
+		switch {
+		case !inNew:
+			d.add(ElementFilter, ChangeRemoved, before.Path, name, nil, nil, true)
+		case !inOld:
+			d.add(ElementFilter, ChangeAdded, before.Path, name, nil, nil, false)
+		default:
+			d.diffFields(ElementFilterField, before.Path, name+".", oldFilter, newFilter)
+		}
+	}
+
+	oldActions := map[string]*SchemaAction{}
+	for _, action := range before.Actions {
+		oldActions[action.Path] = action
+	}
+	newActions := map[string]*SchemaAction{}
+	for _, action := range after.Actions {
+		newActions[action.Path] = action
+	}
+	for _, path := range sortedKeys(oldActions, newActions) {
+		oldAction, inOld := oldActions[path]
+		newAction, inNew := newActions[path]
+		switch {
+		case !inNew:
+			d.add(ElementAction, ChangeRemoved, path, "", nil, nil, true)
+		case !inOld:
+			d.add(ElementAction, ChangeAdded, path, "", nil, nil, false)
+		default:
+			d.diffAction(oldAction, newAction)
+		}
+	}
+}
+
+func (d *SchemaDiff) diffAction(before *SchemaAction, after *SchemaAction) {
+	if before.Static != after.Static {
+		d.add(ElementAction, ChangeStaticChanged, before.Path, "", before.Static, after.Static, true)
+	}
+
+	d.diffFields(ElementParamater, before.Path, "", before.Paramaters, after.Paramaters)
+
+	if before.ReturnType != nil && after.ReturnType != nil {
+		d.diffField(ElementReturnType, before.Path, "", before.ReturnType, after.ReturnType)
+	}
+}
+
+// diffFields compares fields by name, prefix is added to the names in the changes
+func (d *SchemaDiff) diffFields(element string, path string, prefix string, before []*SchemaField, after []*SchemaField) {
+	oldFields := map[string]*SchemaField{}
+	for _, field := range before {
+		oldFields[field.Name] = field
+	}
+	newFields := map[string]*SchemaField{}
+	for _, field := range after {
+		newFields[field.Name] = field
+	}
+
+	for _, name := range sortedKeys(oldFields, newFields) {
+		oldField, inOld := oldFields[name]
+		newField, inNew := newFields[name]
+		switch {
+		case !inNew:
+			d.add(element, ChangeRemoved, path, prefix+name, nil, nil, true)
+		case !inOld: // a new required value breaks anyone not sending it, unless it is read only
+			d.add(element, ChangeAdded, path, prefix+name, nil, nil, newField.Required && newField.Mode != "RO")
+		default:
+			d.diffField(element, path, prefix+name, oldField, newField)
+		}
+	}
+}
+
+func (d *SchemaDiff) diffField(element string, path string, name string, before *SchemaField, after *SchemaField) {
+	if before.Type != after.Type || before.IsArray != after.IsArray || before.URI != after.URI {
+		d.add(element, ChangeTypeChanged, path, name, fieldTypeName(before), fieldTypeName(after), true)
+	}
+
+	if before.Required != after.Required {
+		d.add(element, ChangeRequiredChanged, path, name, before.Required, after.Required, after.Required)
+	}
+
+	if before.Mode != after.Mode { // anything other than going to read/write takes something away
+		d.add(element, ChangeModeChanged, path, name, before.Mode, after.Mode, after.Mode != "RW")
+	}
+
+	if before.Length != after.Length { // longer values are ok, 0 is unlimited
+		d.add(element, ChangeLengthChanged, path, name, before.Length, after.Length, after.Length != 0 && (before.Length == 0 || after.Length < before.Length))
+	}
+
+	if (len(before.Choices) > 0 || len(after.Choices) > 0) && !reflect.DeepEqual(before.Choices, after.Choices) {
+		breaking := len(after.Choices) > 0 && len(before.Choices) == 0 // newly restricted
+		for _, choice := range before.Choices {
+			found := false
+			for _, newChoice := range after.Choices {
+				if reflect.DeepEqual(choice, newChoice) {
+					found = true
+					break
+				}
+			}
+			if !found && len(after.Choices) > 0 {
+				breaking = true
+			}
+		}
+		d.add(element, ChangeChoicesChanged, path, name, before.Choices, after.Choices, breaking)
+	}
+}
+
+func fieldTypeName(field *SchemaField) string {
+	result := field.Type
+	if field.Type == "Model" {
+		result += "(" + field.URI + ")"
+	}
+
+	if field.IsArray {
+		result = "[]" + result
+	}
+
+	return result
+}
+
+func sortedKeys[V any](a map[string]V, b map[string]V) []string {
+	keys := map[string]bool{}
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+
+	result := make([]string, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
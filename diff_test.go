@@ -0,0 +1,144 @@
+package cinp
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func crawlTestAPI(t *testing.T, api map[string]testDescribe) *Schema {
+	server := newTestAPIServer(api, nil)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	schema, err := c.Crawl(context.TODO(), "/api/v1/", 2)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	return schema
+}
+
+func TestSchemaSaveLoad(t *testing.T) {
+	schema := crawlTestAPI(t, testAPI())
+
+	buff := &bytes.Buffer{}
+	if err := schema.Save(buff); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	loaded, err := LoadSchema(buff)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if !reflect.DeepEqual(loaded.Root, schema.Root) {
+		t.Errorf("Loaded schema does not match")
+		t.FailNow()
+	}
+
+	if loaded.Model("/api/v1/Building/Room").Fields[1].Model != loaded.Model("/api/v1/Building/Site") {
+		t.Errorf("Loaded schema not linked")
+		t.FailNow()
+	}
+
+	if len(DiffSchema(schema, loaded).Changes) != 0 {
+		t.Errorf("Expected no changes got '%v'", DiffSchema(schema, loaded).Changes)
+		t.FailNow()
+	}
+
+	if _, err = LoadSchema(bytes.NewBufferString("{}")); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	before := crawlTestAPI(t, testAPI())
+
+	api := testAPI()
+	room := api["/api/v1/Building/Room"]
+	room.describe.Fields = []FieldParamater{
+		{Name: "name", Type: "String", Length: 30, Required: true, Mode: "RC"},                                    // shorter
+		{Name: "size", Type: "Float", Mode: "RW"},                                                                 // type changed
+		{Name: "kind", Type: "String", Length: 10, Choices: []interface{}{"office", "lab", "closet"}, Mode: "RO"}, // choice added, mode changed
+		{Name: "floor", Type: "Integer", Required: true, Mode: "RW"},                                              // added, required
+		{Name: "notes", Type: "String", Mode: "RW"},                                                               // added
+	} // site removed
+	room.describe.Constants = map[string]string{"MAX_SIZE": "200", "MIN_SIZE": "1"}
+	room.describe.NotAllowedMethods = []string{"UPDATE"}
+	room.describe.ListFilters = map[string][]FieldParamater{"site": {{Name: "site", Type: "Model", URI: "/api/v1/Building/Site", Mode: "RW"}}, "kind": {}}
+	api["/api/v1/Building/Room"] = room
+
+	move := api["/api/v1/Building/Room(move)"]
+	move.describe.Static = true
+	move.describe.ReturnType = FieldParamater{Type: "String"}
+	move.describe.Paramaters = []FieldParamater{{Name: "site", Type: "Model", URI: "/api/v1/Building/Site", Required: true}, {Name: "note", Type: "String", Length: 20, Required: true}}
+	api["/api/v1/Building/Room(move)"] = move
+
+	site := api["/api/v1/Building/Site"]
+	site.describe.Actions = []string{}
+	api["/api/v1/Building/Site"] = site
+	delete(api, "/api/v1/Building/Site(summary)")
+
+	root := api["/api/v1/"]
+	root.describe.APIVersion = "1.3"
+	root.describe.Namespaces = append(root.describe.Namespaces, "/api/v1/Auth/")
+	api["/api/v1/"] = root
+	api["/api/v1/Auth/"] = testDescribe{"Namespace", Describe{Name: "Auth", Path: "/api/v1/Auth/", APIVersion: "0.1", Namespaces: []string{}, Models: []string{}}}
+
+	after := crawlTestAPI(t, api)
+
+	diff := DiffSchema(before, after)
+	if !diff.Breaking() {
+		t.Errorf("Expected breaking changes")
+		t.FailNow()
+	}
+
+	type key struct {
+		element string
+		change  string
+		path    string
+		name    string
+	}
+	result := map[key]bool{}
+	for _, change := range diff.Changes {
+		result[key{change.Element, change.Change, change.Path, change.Name}] = change.Breaking
+	}
+
+	roomPath := "/api/v1/Building/Room"
+	expected := map[key]bool{
+		{ElementNamespace, ChangeVersionChanged, "/api/v1/", ""}:                         false,
+		{ElementNamespace, ChangeAdded, "/api/v1/Auth/", ""}:                             false,
+		{ElementField, ChangeLengthChanged, roomPath, "name"}:                            true,
+		{ElementField, ChangeRemoved, roomPath, "site"}:                                  true,
+		{ElementField, ChangeTypeChanged, roomPath, "size"}:                              true,
+		{ElementField, ChangeChoicesChanged, roomPath, "kind"}:                           false,
+		{ElementField, ChangeModeChanged, roomPath, "kind"}:                              true,
+		{ElementField, ChangeAdded, roomPath, "floor"}:                                   true,
+		{ElementField, ChangeAdded, roomPath, "notes"}:                                   false,
+		{ElementConstant, ChangeValueChanged, roomPath, "MAX_SIZE"}:                      false,
+		{ElementConstant, ChangeAdded, roomPath, "MIN_SIZE"}:                             false,
+		{ElementMethod, ChangeRemoved, roomPath, "UPDATE"}:                               true,
+		{ElementMethod, ChangeAdded, roomPath, "DELETE"}:                                 false,
+		{ElementFilter, ChangeAdded, roomPath, "kind"}:                                   false,
+		{ElementFilterField, ChangeRequiredChanged, roomPath, "site.site"}:               false,
+		{ElementAction, ChangeRemoved, "/api/v1/Building/Site(summary)", ""}:             true,
+		{ElementAction, ChangeStaticChanged, "/api/v1/Building/Room(move)", ""}:          true,
+		{ElementReturnType, ChangeTypeChanged, "/api/v1/Building/Room(move)", ""}:        true,
+		{ElementParamater, ChangeRequiredChanged, "/api/v1/Building/Room(move)", "note"}: true,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Changes wrong, got '%v'", diff.Changes)
+		t.FailNow()
+	}
+}
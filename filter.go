@@ -0,0 +1,105 @@
+package cinp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnknownFilter is a error that is returned when a model does not have the requested list filter
+type UnknownFilter struct {
+	Model   string
+	Name    string
+	Filters []string // the filters the model does have
+}
+
+func (e *UnknownFilter) Error() string {
+	if len(e.Filters) == 0 {
+		return fmt.Sprintf("model '%s' does not have filter '%s', it has no filters", e.Model, e.Name)
+	}
+
+	return fmt.Sprintf("model '%s' does not have filter '%s', valid filters are '%s'", e.Model, e.Name, strings.Join(e.Filters, "', '"))
+}
+
+// ListFilter is a list filter that is checked against the model's describe before it is sent, build with NewListFilter
+type ListFilter struct {
+	Model  string
+	Name   string
+	Values map[string]interface{}
+	fields []FieldParamater
+	cinp   *CInP
+}
+
+// NewListFilter describes the model at uri and returns a empty filter named name, name must be one of the model's
+// list-filters.  A name of "" is no filter.
+func (cinp *CInP) NewListFilter(ctx context.Context, uri string, name string) (*ListFilter, error) {
+	u := cinp.uriFor(uri)
+	ns, model, _, _, _, err := u.Split(uri)
+	if err != nil {
+		return nil, err
+	}
+	if model == "" {
+		return nil, fmt.Errorf("'%s' is not a model URI", uri)
+	}
+	if uri, err = u.Build(ns, model, "", nil); err != nil {
+		return nil, err
+	}
+
+	result := &ListFilter{Model: uri, Name: name, Values: map[string]interface{}{}, cinp: cinp}
+	if name == "" {
+		return result, nil
+	}
+
+	describe, describeType, err := cinp.Describe(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if describeType != "Model" {
+		return nil, fmt.Errorf("expected '%s' to be a 'Model' got '%s'", uri, describeType)
+	}
+
+	fieldList, ok := describe.ListFilters[name]
+	if !ok {
+		filters := make([]string, 0, len(describe.ListFilters))
+		for filter := range describe.ListFilters {
+			filters = append(filters, filter)
+		}
+		sort.Strings(filters)
+		return nil, &UnknownFilter{Model: uri, Name: name, Filters: filters}
+	}
+	result.fields = fieldList
+
+	return result, nil
+}
+
+// Set checks value against the filter's field and sets it
+func (f *ListFilter) Set(name string, value interface{}) error {
+	if err := checkNamedValue(f.fields, name, value); err != nil {
+		return fmt.Errorf("filter '%s': %w", f.Name, err)
+	}
+
+	f.Values[name] = value
+
+	return nil
+}
+
+// Check checks all the values, including that the required ones are set.  Use Check before passing Name and
+// Values to ListIds or ListObjects
+func (f *ListFilter) Check() error {
+	if err := checkValues(f.fields, f.Values); err != nil {
+		return fmt.Errorf("filter '%s': %w", f.Name, err)
+	}
+
+	return nil
+}
+
+// List checks the filter and lists the filter's model with it, see List
+func (f *ListFilter) List(ctx context.Context, position int, count int) ([]string, int, int, int, error) {
+	if err := f.Check(); err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	return f.cinp.List(ctx, f.Model, f.Name, f.Values, position, count)
+}
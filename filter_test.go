@@ -0,0 +1,105 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestListFilter(t *testing.T) {
+	var gotFilter string
+	var gotValues map[string]interface{}
+	server := newTestAPIServer(testAPI(), func(rw http.ResponseWriter, req *http.Request) {
+		gotFilter = req.Header.Get("Filter")
+		gotValues = map[string]interface{}{}
+		json.NewDecoder(req.Body).Decode(&gotValues)
+		rw.Header().Set("Position", "0")
+		rw.Header().Set("Count", "1")
+		rw.Header().Set("Total", "1")
+		json.NewEncoder(rw).Encode([]string{"/api/v1/Building/Room:1:"})
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+
+	_, err = c.NewListFilter(ctx, "/api/v1/Building/Room", "sit")
+	var unknown *UnknownFilter
+	if !errors.As(err, &unknown) || !reflect.DeepEqual(unknown.Filters, []string{"site"}) {
+		t.Errorf("Expected UnknownFilter got '%v'", err)
+		t.FailNow()
+	}
+	if err.Error() != "model '/api/v1/Building/Room' does not have filter 'sit', valid filters are 'site'" {
+		t.Errorf("Wrong error message '%s'", err)
+		t.FailNow()
+	}
+
+	if _, err = c.NewListFilter(ctx, "/api/v1/Building/", "site"); err == nil {
+		t.Errorf("error missing for namespace")
+		t.FailNow()
+	}
+
+	filter, err := c.NewListFilter(ctx, "/api/v1/Building/Room:5:", "site")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if filter.Model != "/api/v1/Building/Room" {
+		t.Errorf("Expected model URI got '%s'", filter.Model)
+		t.FailNow()
+	}
+
+	if _, _, _, _, err = filter.List(ctx, 0, 10); err == nil || err.Error() != "filter 'site': invalid value for 'site': required" {
+		t.Errorf("Expected required error got '%v'", err)
+		t.FailNow()
+	}
+
+	if err = filter.Set("site", "/api/v1/Building/Room:1:"); err == nil {
+		t.Errorf("error missing for wrong model")
+		t.FailNow()
+	}
+	if err = filter.Set("name", "bob"); err == nil {
+		t.Errorf("error missing for unknown value")
+		t.FailNow()
+	}
+	if gotFilter != "" {
+		t.Errorf("Request sent for invalid filter")
+		t.FailNow()
+	}
+
+	if err = filter.Set("site", "/api/v1/Building/Site:1:"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	uriList, _, _, total, err := filter.List(ctx, 0, 10)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if total != 1 || !reflect.DeepEqual(uriList, []string{"/api/v1/Building/Room:1:"}) {
+		t.Errorf("Wrong result '%v' %d", uriList, total)
+		t.FailNow()
+	}
+	if gotFilter != "site" || !reflect.DeepEqual(gotValues, map[string]interface{}{"site": "/api/v1/Building/Site:1:"}) {
+		t.Errorf("Wrong filter sent '%s' '%v'", gotFilter, gotValues)
+		t.FailNow()
+	}
+
+	filter, err = c.NewListFilter(ctx, "/api/v1/Building/Room", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if err = filter.Set("site", "/api/v1/Building/Site:1:"); err == nil {
+		t.Errorf("error missing for value with no filter")
+		t.FailNow()
+	}
+}
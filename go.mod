@@ -1,3 +1,11 @@
 module github.com/cinp/go
 
-go 1.21
+go 1.22
+
+require github.com/klauspost/compress v1.18.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require github.com/chzyer/readline v1.5.1
+
+require golang.org/x/sys v0.0.0-20220310020820-b874c991c1a5 // indirect
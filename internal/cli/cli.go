@@ -0,0 +1,60 @@
+// Package cli is the flag and command handling shared by the cinp and cinp-schema commands
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HeaderList is a flag.Value of headers in the form Name=Value, it can be repeated
+type HeaderList map[string]string
+
+func (h HeaderList) String() string {
+	return fmt.Sprintf("%v", map[string]string(h))
+}
+
+// Set adds the header in value
+func (h HeaderList) Set(value string) error {
+	name, value, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return errors.New("header must be in the form Name=Value")
+	}
+	h[name] = value
+	return nil
+}
+
+// Command is a sub command, Usage is the arguments and a description on the following tab indented lines
+type Command struct {
+	Usage string
+	Run   func(ctx context.Context, args []string) error
+}
+
+// Usage writes the flags and commands to the flag output, footer is written after the commands if it is not ""
+func Usage(commands map[string]Command, footer string) {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: %s [flags] COMMAND [ARGS]\n\nflags:\n", filepath.Base(os.Args[0]))
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\ncommands:\n")
+	for _, name := range SortedCommands(commands) {
+		fmt.Fprintf(out, "  %s\n", commands[name].Usage)
+	}
+	if footer != "" {
+		fmt.Fprintf(out, "\n%s\n", footer)
+	}
+}
+
+// SortedCommands returns the names of the commands in order
+func SortedCommands(commands map[string]Command) []string {
+	result := []string{}
+	for name := range commands {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
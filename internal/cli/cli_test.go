@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeaderList(t *testing.T) {
+	headers := HeaderList{}
+	for _, value := range []string{"Auth-Id=bob", "X-Empty=", "X-Eq=a=b"} {
+		if err := headers.Set(value); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+	}
+	if !reflect.DeepEqual(headers, HeaderList{"Auth-Id": "bob", "X-Empty": "", "X-Eq": "a=b"}) {
+		t.Errorf("Wrong headers '%v'", headers)
+		t.FailNow()
+	}
+
+	for _, value := range []string{"bad", "=value"} {
+		if err := headers.Set(value); err == nil {
+			t.Errorf("error missing for '%s'", value)
+			t.FailNow()
+		}
+	}
+}
+
+func TestSortedCommands(t *testing.T) {
+	commands := map[string]Command{"b": {}, "c": {}, "a": {}}
+	if result := SortedCommands(commands); !reflect.DeepEqual(result, []string{"a", "b", "c"}) {
+		t.Errorf("Expected '[a b c]' got '%v'", result)
+		t.FailNow()
+	}
+}
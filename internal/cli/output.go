@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteJSON writes value as indented JSON
+func WriteJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// WriteYAML goes through JSON so the json tags are used for the names, and nothing is written that
+// JSON would not also write
+func WriteYAML(w io.Writer, value interface{}) error {
+	buff, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(buff, node); err != nil {
+		return err
+	}
+	blockStyle(node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// blockStyle clears the flow and quoting styles the JSON was parsed with, so the YAML looks like YAML
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
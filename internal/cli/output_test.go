@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteYAML(t *testing.T) {
+	value := struct {
+		Name string        `json:"name"`
+		List []interface{} `json:"list"`
+		Skip string        `json:"skip,omitempty"`
+	}{Name: "a <b>", List: []interface{}{1, "2"}}
+
+	buff := &bytes.Buffer{}
+	if err := WriteYAML(buff, value); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	expected := "name: a <b>\nlist:\n  - 1\n  - \"2\"\n"
+	if buff.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, buff.String())
+		t.FailNow()
+	}
+
+	buff.Reset()
+	if err := WriteJSON(buff, value); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	expected = "{\n  \"name\": \"a <b>\",\n  \"list\": [\n    1,\n    \"2\"\n  ]\n}\n"
+	if buff.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, buff.String())
+		t.FailNow()
+	}
+}
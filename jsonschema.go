@@ -0,0 +1,123 @@
+package cinp
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// JSONSchemaDialect is the JSON Schema version of the schemas ModelJSONSchema, ActionJSONSchema and DescribeJSONSchema produce
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// fieldSchema maps a Field/Paramater to a JSON Schema (which OpenAPI 3.1+ uses), modelRef returns the schema for a Model
+// type field's URI, it should handle URIs of models that are not known
+func fieldSchema(field FieldParamater, modelRef func(uri string) map[string]interface{}) map[string]interface{} {
+	var result map[string]interface{}
+	switch field.Type {
+	case "String":
+		result = map[string]interface{}{"type": "string"}
+		if field.Length > 0 {
+			result["maxLength"] = field.Length
+		}
+	case "Integer":
+		result = map[string]interface{}{"type": "integer"}
+	case "Float":
+		result = map[string]interface{}{"type": "number"}
+	case "Boolean":
+		result = map[string]interface{}{"type": "boolean"}
+	case "DateTime":
+		result = map[string]interface{}{"type": "string", "format": "date-time"}
+	case "Map":
+		result = map[string]interface{}{"type": "object"}
+	case "Model":
+		result = modelRef(field.URI)
+	case "File":
+		result = map[string]interface{}{"type": "string", "format": "uri-reference"}
+	default:
+		result = map[string]interface{}{}
+	}
+
+	if len(field.Choices) > 0 {
+		result["enum"] = field.Choices
+	}
+
+	if field.IsArray {
+		result = map[string]interface{}{"type": "array", "items": result}
+	}
+
+	if field.Doc != "" {
+		result["description"] = field.Doc
+	}
+
+	if field.Default != nil {
+		result["default"] = field.Default
+	}
+
+	if field.Mode == "RO" {
+		result["readOnly"] = true
+	}
+
+	return result
+}
+
+// objectSchema is a JSON Schema object with a property for each field
+func objectSchema(fieldList []*SchemaField, modelRef func(uri string) map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, field := range fieldList {
+		properties[field.Name] = fieldSchema(field.FieldParamater, modelRef)
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	result := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+
+	return result
+}
+
+// modelURISchema is for Model type fields, on the wire they are the URI of the object
+func modelURISchema(uri string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"format":      "uri-reference",
+		"pattern":     "^" + regexp.QuoteMeta(uri) + ":[^:]*:$",
+		"description": "URI of a " + uri,
+	}
+}
+
+func rootJSONSchema(title string, doc string, fieldList []*SchemaField) map[string]interface{} {
+	result := objectSchema(fieldList, modelURISchema)
+	result["$schema"] = JSONSchemaDialect
+	result["title"] = title
+	result["additionalProperties"] = false
+	if doc != "" {
+		result["description"] = doc
+	}
+
+	return result
+}
+
+// ModelJSONSchema is the JSON Schema for the values of a model's object, Model type fields are the URI of the object
+func ModelJSONSchema(model *SchemaModel) map[string]interface{} {
+	return rootJSONSchema(model.Name, model.Doc, model.Fields)
+}
+
+// ActionJSONSchema is the JSON Schema for the paramaters of a action
+func ActionJSONSchema(action *SchemaAction) map[string]interface{} {
+	return rootJSONSchema(action.Name, action.Doc, action.Paramaters)
+}
+
+// DescribeJSONSchema is the JSON Schema for a Model or Action Describe, the describeType is the type returned by Describe
+func DescribeJSONSchema(describe *Describe, describeType string) (map[string]interface{}, error) {
+	switch describeType {
+	case "Model":
+		return rootJSONSchema(describe.Name, describe.Doc, newSchemaFields(describe.Fields)), nil
+	case "Action":
+		return rootJSONSchema(describe.Name, describe.Doc, newSchemaFields(describe.Paramaters)), nil
+	}
+
+	return nil, fmt.Errorf("can not make a JSON Schema for a '%s'", describeType)
+}
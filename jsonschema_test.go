@@ -0,0 +1,117 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFieldSchema(t *testing.T) {
+	modelRef := func(uri string) map[string]interface{} { return map[string]interface{}{"$ref": uri} }
+
+	var fieldList = []struct {
+		field  FieldParamater
+		result map[string]interface{}
+	}{
+		{FieldParamater{Type: "String"}, map[string]interface{}{"type": "string"}},
+		{FieldParamater{Type: "String", Length: 5, Default: "a", Doc: "stuff"}, map[string]interface{}{"type": "string", "maxLength": 5, "default": "a", "description": "stuff"}},
+		{FieldParamater{Type: "Integer", Mode: "RO"}, map[string]interface{}{"type": "integer", "readOnly": true}},
+		{FieldParamater{Type: "Float"}, map[string]interface{}{"type": "number"}},
+		{FieldParamater{Type: "Boolean"}, map[string]interface{}{"type": "boolean"}},
+		{FieldParamater{Type: "DateTime"}, map[string]interface{}{"type": "string", "format": "date-time"}},
+		{FieldParamater{Type: "Map"}, map[string]interface{}{"type": "object"}},
+		{FieldParamater{Type: "Model", URI: "/api/m"}, map[string]interface{}{"$ref": "/api/m"}},
+		{FieldParamater{Type: "Model", URI: "/api/m", IsArray: true}, map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "/api/m"}}},
+		{FieldParamater{Type: "Integer", IsArray: true, Choices: []interface{}{1, 2}}, map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer", "enum": []interface{}{1, 2}}}},
+		{FieldParamater{Type: "File"}, map[string]interface{}{"type": "string", "format": "uri-reference"}},
+	}
+	for _, v := range fieldList {
+		result := fieldSchema(v.field, modelRef)
+		if !reflect.DeepEqual(result, v.result) {
+			t.Errorf("Expected '%v' got '%v' for '%+v'", v.result, result, v.field)
+			t.FailNow()
+		}
+	}
+}
+
+func TestModelActionJSONSchema(t *testing.T) {
+	schema := crawlTestAPI(t, testAPI())
+
+	room := ModelJSONSchema(schema.Model("/api/v1/Building/Room"))
+	if room["$schema"] != JSONSchemaDialect || room["title"] != "Room" || room["description"] != "A Room" || room["additionalProperties"] != false {
+		t.Errorf("Room schema wrong, got '%v'", room)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(room["required"], []string{"name", "site"}) {
+		t.Errorf("Room required wrong, got '%v'", room["required"])
+		t.FailNow()
+	}
+	properties := room["properties"].(map[string]interface{})
+	if !reflect.DeepEqual(properties["site"], modelURISchema("/api/v1/Building/Site")) {
+		t.Errorf("Room.site wrong, got '%v'", properties["site"])
+		t.FailNow()
+	}
+
+	move := ActionJSONSchema(schema.Model("/api/v1/Building/Room").Actions[0])
+	if move["title"] != "move" || !reflect.DeepEqual(move["required"], []string{"site"}) {
+		t.Errorf("Room(move) schema wrong, got '%v'", move)
+		t.FailNow()
+	}
+	properties = move["properties"].(map[string]interface{})
+	if !reflect.DeepEqual(properties["note"], map[string]interface{}{"type": "string", "maxLength": 20}) {
+		t.Errorf("Room(move).note wrong, got '%v'", properties["note"])
+		t.FailNow()
+	}
+
+	if _, err := json.Marshal(move); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+}
+
+func TestDescribeJSONSchema(t *testing.T) {
+	server := newTestAPIServer(testAPI(), nil)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	schema := crawlTestAPI(t, testAPI())
+
+	for _, v := range []string{"/api/v1/Building/Room", "/api/v1/Building/Site(summary)"} {
+		describe, describeType, err := c.Describe(context.TODO(), v)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		result, err := DescribeJSONSchema(describe, describeType)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		var expected map[string]interface{}
+		if describeType == "Model" {
+			expected = ModelJSONSchema(schema.Model(v))
+		} else {
+			expected = ActionJSONSchema(schema.Model("/api/v1/Building/Site").Actions[0])
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Expected '%v' got '%v'", expected, result)
+			t.FailNow()
+		}
+	}
+
+	describe, describeType, err := c.Describe(context.TODO(), "/api/v1/")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, err := DescribeJSONSchema(describe, describeType); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,305 @@
+package cinp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// get returns the value of the field name, InvalidValue if it is not set
+func (mo *MappedObject) get(name string) (interface{}, error) {
+	value, ok := mo.Data[name]
+	if !ok {
+		return nil, &InvalidValue{Name: name, Reason: "not set"}
+	}
+
+	return value, nil
+}
+
+func (mo *MappedObject) set(name string, value interface{}) {
+	if mo.Data == nil {
+		mo.Data = map[string]interface{}{}
+	}
+	mo.Data[name] = value
+}
+
+func typeMismatch(name string, expected string, value interface{}) error {
+	return &InvalidValue{Name: name, Reason: fmt.Sprintf("expected a %s got %T '%v'", expected, value, value)}
+}
+
+// GetString returns the String field name
+func (mo *MappedObject) GetString(name string) (string, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return "", err
+	}
+
+	result, ok := value.(string)
+	if !ok {
+		return "", typeMismatch(name, "String", value)
+	}
+
+	return result, nil
+}
+
+// GetInt returns the Integer field name, a float with a fractional part is an error
+func (mo *MappedObject) GetInt(name string) (int64, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		if result, err := v.Int64(); err == nil {
+			return result, nil
+		}
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), nil
+		}
+
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), nil
+		}
+
+	case int, int8, int16, int32, int64:
+		return reflect.ValueOf(v).Int(), nil
+	}
+
+	return 0, typeMismatch(name, "Integer", value)
+}
+
+// GetFloat returns the Float field name, Integer values are converted
+func (mo *MappedObject) GetFloat(name string) (float64, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := value.(bool); !ok {
+		if result, ok := toFloat(value); ok {
+			return result, nil
+		}
+	}
+
+	return 0, typeMismatch(name, "Float", value)
+}
+
+// GetBool returns the Boolean field name
+func (mo *MappedObject) GetBool(name string) (bool, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := value.(bool)
+	if !ok {
+		return false, typeMismatch(name, "Boolean", value)
+	}
+
+	return result, nil
+}
+
+// GetTime returns the DateTime field name, values with out a timezone are UTC
+func (mo *MappedObject) GetTime(name string) (time.Time, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+
+	case DateTime:
+		return v.Time, nil
+
+	case string:
+		result, err := parseDateTime(v)
+		if err != nil {
+			return time.Time{}, &InvalidValue{Name: name, Reason: err.Error()}
+		}
+		return result, nil
+	}
+
+	return time.Time{}, typeMismatch(name, "DateTime", value)
+}
+
+// GetList returns the array field name
+func (mo *MappedObject) GetList(name string) ([]interface{}, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := value.([]interface{})
+	if !ok {
+		return nil, typeMismatch(name, "list", value)
+	}
+
+	return result, nil
+}
+
+// GetModelURI returns the URI of the Model field name, "" if the field is null
+func (mo *MappedObject) GetModelURI(name string) (string, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case ModelRef:
+		return v.URI, nil
+	case *ModelRef:
+		return v.URI, nil
+	}
+
+	return "", typeMismatch(name, "Model", value)
+}
+
+// GetMap returns the Map field name
+func (mo *MappedObject) GetMap(name string) (map[string]interface{}, error) {
+	value, err := mo.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case Map:
+		return v, nil
+	}
+
+	return nil, typeMismatch(name, "Map", value)
+}
+
+// SetString sets the String field name
+func (mo *MappedObject) SetString(name string, value string) {
+	mo.set(name, value)
+}
+
+// SetInt sets the Integer field name, as a json.Number so large values keep their precision
+func (mo *MappedObject) SetInt(name string, value int64) {
+	mo.set(name, json.Number(strconv.FormatInt(value, 10)))
+}
+
+// SetFloat sets the Float field name
+func (mo *MappedObject) SetFloat(name string, value float64) {
+	mo.set(name, value)
+}
+
+// SetBool sets the Boolean field name
+func (mo *MappedObject) SetBool(name string, value bool) {
+	mo.set(name, value)
+}
+
+// SetTime sets the DateTime field name, in the format of python's isoformat
+func (mo *MappedObject) SetTime(name string, value time.Time) {
+	mo.set(name, value.Format(dateTimeFormat))
+}
+
+// SetList sets the array field name
+func (mo *MappedObject) SetList(name string, value []interface{}) {
+	mo.set(name, value)
+}
+
+// SetModelURI sets the Model field name to the URI, "" sets it to null
+func (mo *MappedObject) SetModelURI(name string, uri string) {
+	if uri == "" {
+		mo.set(name, nil)
+		return
+	}
+	mo.set(name, uri)
+}
+
+// SetMap sets the Map field name
+func (mo *MappedObject) SetMap(name string, value map[string]interface{}) {
+	mo.set(name, value)
+}
+
+// MarshalJSON encodes the fields, so a MappedObject can be sent with Create and Update
+func (mo MappedObject) MarshalJSON() ([]byte, error) {
+	if mo.Data == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(mo.Data)
+}
+
+// UnmarshalJSON decodes the fields, see Map
+func (mo *MappedObject) UnmarshalJSON(data []byte) error {
+	return (*Map)(&mo.Data).UnmarshalJSON(data)
+}
+
+// Into copies the fields into object, which is usually a registered type, through JSON.  The URI is copied.
+func (mo *MappedObject) Into(object Object) error {
+	if _, ok := object.(*MappedObject); ok {
+		return fmt.Errorf("can not convert into a MappedObject, use NewMappedObject")
+	}
+
+	buff, err := json.Marshal(mo.Data)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(buff, object); err != nil {
+		return fmt.Errorf("unable to convert '%s' to %T: %w", mo.GetURI(), object, err)
+	}
+
+	object.SetURI(mo.GetURI())
+
+	return nil
+}
+
+// NewMappedObject returns a MappedObject with the fields of object, through JSON.  The URI is copied.
+func NewMappedObject(object Object) (*MappedObject, error) {
+	var value interface{} = object
+	if mo, ok := object.(*MappedObject); ok {
+		value = mo.Data
+	}
+
+	buff, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MappedObject{}
+	if err := decodeJSON(buff, &result.Data); err != nil {
+		return nil, fmt.Errorf("unable to convert %T: %w", object, err)
+	}
+	if result.Data == nil {
+		result.Data = map[string]interface{}{}
+	}
+
+	result.SetURI(object.GetURI())
+
+	return result, nil
+}
+
+// ConvertMapped converts mo to the type registered for it's URI (see RegisterType), if no type is registered
+// mo is returned, unless strict types are on
+func (cinp *CInP) ConvertMapped(mo *MappedObject) (Object, error) {
+	result, err := cinp.newObject(mo.GetURI())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := result.(*MappedObject); ok {
+		return mo, nil
+	}
+
+	if err := mo.Into(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,233 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMappedObjectGetters(t *testing.T) {
+	mo := &MappedObject{}
+	if err := json.Unmarshal([]byte(`{"name": "bob", "count": 12, "big": 9007199254740993, "ratio": 1.5, "active": true, "created": "2024-01-02T03:04:05.123456+00:00", "tags": ["a", "b"], "site": "/api/v1/Building/Site:1:", "none": null, "extra": {"a": 1}}`), &mo.Data); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	mo.Data["big"] = json.Number("9007199254740993")
+
+	if v, err := mo.GetString("name"); err != nil || v != "bob" {
+		t.Errorf("Wrong string '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetInt("count"); err != nil || v != 12 {
+		t.Errorf("Wrong int '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetInt("big"); err != nil || v != 9007199254740993 {
+		t.Errorf("Wrong int '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetFloat("ratio"); err != nil || v != 1.5 {
+		t.Errorf("Wrong float '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetFloat("count"); err != nil || v != 12 {
+		t.Errorf("Wrong float '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetBool("active"); err != nil || !v {
+		t.Errorf("Wrong bool '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetTime("created"); err != nil || !v.Equal(time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)) {
+		t.Errorf("Wrong time '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetList("tags"); err != nil || !reflect.DeepEqual(v, []interface{}{"a", "b"}) {
+		t.Errorf("Wrong list '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetModelURI("site"); err != nil || v != "/api/v1/Building/Site:1:" {
+		t.Errorf("Wrong model '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetModelURI("none"); err != nil || v != "" {
+		t.Errorf("Wrong model '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetMap("extra"); err != nil || !reflect.DeepEqual(v, map[string]interface{}{"a": float64(1)}) {
+		t.Errorf("Wrong map '%v' '%v'", v, err)
+		t.FailNow()
+	}
+
+	tests := map[string]func() error{
+		"name:Integer":   func() error { _, err := mo.GetInt("name"); return err },
+		"ratio:Integer":  func() error { _, err := mo.GetInt("ratio"); return err },
+		"count:String":   func() error { _, err := mo.GetString("count"); return err },
+		"active:Float":   func() error { _, err := mo.GetFloat("active"); return err },
+		"name:Boolean":   func() error { _, err := mo.GetBool("name"); return err },
+		"name:DateTime":  func() error { _, err := mo.GetTime("name"); return err },
+		"name:list":      func() error { _, err := mo.GetList("name"); return err },
+		"count:Model":    func() error { _, err := mo.GetModelURI("count"); return err },
+		"tags:Map":       func() error { _, err := mo.GetMap("tags"); return err },
+		"missing:String": func() error { _, err := mo.GetString("missing"); return err },
+	}
+	for key, fn := range tests {
+		name := strings.Split(key, ":")[0]
+		err := fn()
+		var invalid *InvalidValue
+		if !errors.As(err, &invalid) || invalid.Name != name {
+			t.Errorf("Expected InvalidValue for '%s' got '%v'", key, err)
+			t.FailNow()
+		}
+	}
+}
+
+func TestMappedObjectSetters(t *testing.T) {
+	mo := &MappedObject{}
+	mo.SetString("name", "bob")
+	mo.SetInt("big", 9007199254740993)
+	mo.SetFloat("ratio", 2.5)
+	mo.SetBool("active", false)
+	mo.SetTime("created", time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", -7*3600)))
+	mo.SetList("tags", []interface{}{"a"})
+	mo.SetModelURI("site", "/api/v1/Building/Site:1:")
+	mo.SetModelURI("none", "")
+	mo.SetMap("extra", map[string]interface{}{"a": "b"})
+
+	buff, err := json.Marshal(mo.Data)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	expected := `{"active":false,"big":9007199254740993,"created":"2024-01-02T03:04:05-07:00","extra":{"a":"b"},"name":"bob","none":null,"ratio":2.5,"site":"/api/v1/Building/Site:1:","tags":["a"]}`
+	if string(buff) != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, buff)
+		t.FailNow()
+	}
+
+	if v, err := mo.GetInt("big"); err != nil || v != 9007199254740993 {
+		t.Errorf("Wrong int '%v' '%v'", v, err)
+		t.FailNow()
+	}
+}
+
+func TestMappedObjectConvert(t *testing.T) {
+	c, err := NewCInP(getLogger(), "http://localhost", "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	c.RegisterType("/api/v1/Building/Room", reflect.TypeOf((*testRoom)(nil)).Elem())
+
+	mo := &MappedObject{Data: map[string]interface{}{"name": "lab", "size": json.Number("4")}}
+	mo.SetURI("/api/v1/Building/Room:1:")
+
+	object, err := c.ConvertMapped(mo)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	room, ok := object.(*testRoom)
+	if !ok || room.Name != "lab" || room.Size != 4 || room.GetURI() != "/api/v1/Building/Room:1:" {
+		t.Errorf("Wrong object '%+v'", object)
+		t.FailNow()
+	}
+
+	back, err := NewMappedObject(room)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(back.Data, mo.Data) || back.GetURI() != mo.GetURI() {
+		t.Errorf("Wrong round trip '%v' '%s'", back.Data, back.GetURI())
+		t.FailNow()
+	}
+
+	other := &MappedObject{Data: map[string]interface{}{"a": "b"}}
+	other.SetURI("/api/v1/Building/Site:1:")
+	if object, err := c.ConvertMapped(other); err != nil || object != other {
+		t.Errorf("Expected the same MappedObject got '%v' '%v'", object, err)
+		t.FailNow()
+	}
+
+	if err := mo.Into(&MappedObject{}); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	mo.Data["size"] = "big"
+	if err := mo.Into(&testRoom{}); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestMappedObjectUseNumber(t *testing.T) {
+	server := newTestAPIServer(testAPI(), func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(`{"id": 9007199254740993, "ratio": 0.5}`))
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	object, err := c.Get(context.TODO(), "/api/v1/Building/Site:1:")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	mo := (*object).(*MappedObject)
+	if v, err := mo.GetInt("id"); err != nil || v != 9007199254740993 {
+		t.Errorf("Wrong int '%v' '%v'", v, err)
+		t.FailNow()
+	}
+	if v, err := mo.GetFloat("ratio"); err != nil || v != 0.5 {
+		t.Errorf("Wrong float '%v' '%v'", v, err)
+		t.FailNow()
+	}
+}
+
+func TestMappedObjectCreate(t *testing.T) {
+	var gotValues map[string]interface{}
+	server := newTestAPIServer(testAPI(), func(rw http.ResponseWriter, req *http.Request) {
+		json.NewDecoder(req.Body).Decode(&gotValues)
+		rw.Header().Set("Object-Id", "/api/v1/Building/Site:5:")
+		rw.WriteHeader(201)
+		rw.Write([]byte(`{"name": "new", "id": 5}`))
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	mo := &MappedObject{}
+	mo.SetString("name", "new")
+	object, err := c.Create(context.TODO(), "/api/v1/Building/Site", mo)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if !reflect.DeepEqual(gotValues, map[string]interface{}{"name": "new"}) {
+		t.Errorf("Wrong values sent '%v'", gotValues)
+		t.FailNow()
+	}
+
+	result := (*object).(*MappedObject)
+	if id, err := result.GetInt("id"); err != nil || id != 5 || result.GetURI() != "/api/v1/Building/Site:5:" {
+		t.Errorf("Wrong object '%v' '%s'", result.Data, result.GetURI())
+		t.FailNow()
+	}
+}
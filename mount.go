@@ -0,0 +1,80 @@
+package cinp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// mount is a root path of the API, with it's own URI parser and type registry
+type mount struct {
+	uri          *URI
+	typeRegistry *typeRegistry
+}
+
+// AddMount adds another root path to the client, ie "/api/v2/" for a server that also serves "/api/v1/".  Requests
+// are routed by the URI, to the mount with the longest root path the URI starts with.  Each mount has it's own
+// type registry, the host, headers and other settings are shared.  The describe cache's API Version check is only
+// done against the rootPath passed to NewCInP.
+func (cinp *CInP) AddMount(rootPath string) error {
+	for _, item := range cinp.mounts {
+		if item.uri.rootPath == rootPath {
+			return fmt.Errorf("root path '%s' is already mounted", rootPath)
+		}
+	}
+
+	uri, err := NewURI(rootPath)
+	if err != nil {
+		return err
+	}
+
+	cinp.log.Debug("Add Mount", "root path", rootPath)
+
+	cinp.mounts = append(cinp.mounts, &mount{uri: uri, typeRegistry: newTypeRegistry()})
+	sort.SliceStable(cinp.mounts, func(i, j int) bool {
+		return len(cinp.mounts[i].uri.rootPath) > len(cinp.mounts[j].uri.rootPath)
+	})
+
+	return nil
+}
+
+// Mounts returns the root paths of the client, longest first
+func (cinp *CInP) Mounts() []string {
+	result := make([]string, len(cinp.mounts))
+	for i, item := range cinp.mounts {
+		result[i] = item.uri.rootPath
+	}
+
+	return result
+}
+
+// MountURI returns the URI for the root path, nil if the root path is not mounted
+func (cinp *CInP) MountURI(rootPath string) *URI {
+	for _, item := range cinp.mounts {
+		if item.uri.rootPath == rootPath {
+			return item.uri
+		}
+	}
+
+	return nil
+}
+
+// mountFor returns the mount for the uri, the mount of the rootPath passed to NewCInP if no mount matches
+func (cinp *CInP) mountFor(uri string) *mount {
+	var result *mount
+	for _, item := range cinp.mounts {
+		if strings.HasPrefix(uri, item.uri.rootPath) {
+			return item
+		}
+		if item.uri == cinp.uri {
+			result = item
+		}
+	}
+
+	return result
+}
+
+// uriFor returns the URI parser for the uri, see mountFor
+func (cinp *CInP) uriFor(uri string) *URI {
+	return cinp.mountFor(uri).uri
+}
@@ -0,0 +1,109 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type testSiteV2 struct {
+	BaseObject
+	Title string `json:"title"`
+}
+
+func TestMounts(t *testing.T) {
+	api := testAPI()
+	api["/api/v2/Building/Site(summary)"] = api["/api/v1/Building/Site(summary)"]
+
+	var authHeaders []string
+	server := newTestAPIServer(api, func(rw http.ResponseWriter, req *http.Request) {
+		authHeaders = append(authHeaders, req.Header.Get("Auth-Token"))
+		switch req.URL.Path {
+		case "/api/v1/Building/Site:1:":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"name": "one"})
+		case "/api/v2/Building/Site:1:":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"title": "one"})
+		case "/api/v2/Building/Site(summary)":
+			json.NewEncoder(rw).Encode(map[string]interface{}{"rooms": 2})
+		}
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+
+	for _, v := range []string{"/api/v1/", "api/v2"} {
+		if err := c.AddMount(v); err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+	}
+
+	for _, v := range []string{"/api/v2/", "/", "/api/v2/extra/"} {
+		if err := c.AddMount(v); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+	}
+
+	if !reflect.DeepEqual(c.Mounts(), []string{"/api/v2/extra/", "/api/v1/", "/api/v2/", "/"}) {
+		t.Errorf("Wrong mounts '%v'", c.Mounts())
+		t.FailNow()
+	}
+	if c.MountURI("/api/v2/") == nil || c.MountURI("/api/v3/") != nil || c.GetURI() != c.MountURI("/api/v1/") {
+		t.Errorf("Wrong MountURI")
+		t.FailNow()
+	}
+
+	for uri, rootPath := range map[string]string{"/api/v1/ns/Model": "/api/v1/", "/api/v2/ns/Model": "/api/v2/", "/api/v2/extra/Model": "/api/v2/extra/", "/other/Model": "/"} {
+		if c.uriFor(uri).rootPath != rootPath {
+			t.Errorf("Expected '%s' for '%s' got '%s'", rootPath, uri, c.uriFor(uri).rootPath)
+			t.FailNow()
+		}
+	}
+
+	c.RegisterType("/api/v1/Building/Site", reflect.TypeOf((*testSite)(nil)).Elem())
+	c.RegisterType("/api/v2/Building/Site", reflect.TypeOf((*testSiteV2)(nil)).Elem())
+	c.SetHeader("Auth-Token", "secret")
+
+	object, err := c.Get(ctx, "/api/v1/Building/Site:1:")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if site, ok := (*object).(*testSite); !ok || site.Name != "one" {
+		t.Errorf("Wrong object '%+v'", *object)
+		t.FailNow()
+	}
+
+	object, err = c.Get(ctx, "/api/v2/Building/Site:1:")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if site, ok := (*object).(*testSiteV2); !ok || site.Title != "one" || site.GetURI() != "/api/v2/Building/Site:1:" {
+		t.Errorf("Wrong object '%+v'", *object)
+		t.FailNow()
+	}
+
+	summary := map[string]int{}
+	if err = c.CallAction(ctx, "/api/v2/Building/Site(summary)", nil, &summary); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if summary["rooms"] != 2 {
+		t.Errorf("Wrong summary '%v'", summary)
+		t.FailNow()
+	}
+
+	if !reflect.DeepEqual(authHeaders, []string{"secret", "secret", "secret"}) {
+		t.Errorf("Headers not shared '%v'", authHeaders)
+		t.FailNow()
+	}
+}
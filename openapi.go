@@ -0,0 +1,286 @@
+package cinp
+
+import "fmt"
+
+// Versions of the OpenAPI Specification NewOpenAPIVersion can produce.  OpenAPIVersion is the default, as it is
+// what most tools understand, the CInP methods other than GET and DELETE (LIST, CREATE, UPDATE, CALL) are in the
+// "x-cinp-operations" extension of the path.  OpenAPIVersion32 puts them in 3.2's additionalOperations.
+const (
+	OpenAPIVersion   = "3.1.0"
+	OpenAPIVersion32 = "3.2.0"
+)
+
+// OpenAPIDocument is a OpenAPI document, only the parts needed to describe a CInP API are included
+type OpenAPIDocument struct {
+	OpenAPI    string                      `json:"openapi"`
+	Info       OpenAPIInfo                 `json:"info"`
+	Servers    []OpenAPIServer             `json:"servers,omitempty"`
+	Paths      map[string]*OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents           `json:"components"`
+}
+
+// OpenAPIInfo is the OpenAPI Info Object
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer is the OpenAPI Server Object
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIPathItem is the OpenAPI Path Item Object
+type OpenAPIPathItem struct {
+	Summary              string                       `json:"summary,omitempty"`
+	Parameters           []*OpenAPIParameter          `json:"parameters,omitempty"`
+	Get                  *OpenAPIOperation            `json:"get,omitempty"`
+	Delete               *OpenAPIOperation            `json:"delete,omitempty"`
+	AdditionalOperations map[string]*OpenAPIOperation `json:"additionalOperations,omitempty"` // 3.2
+	CInPOperations       map[string]*OpenAPIOperation `json:"x-cinp-operations,omitempty"`    // before 3.2
+}
+
+// OpenAPIOperation is the OpenAPI Operation Object
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter is the OpenAPI Parameter Object
+type OpenAPIParameter struct {
+	Name        string                 `json:"name"`
+	In          string                 `json:"in"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// OpenAPIRequestBody is the OpenAPI Request Body Object
+type OpenAPIRequestBody struct {
+	Description string                       `json:"description,omitempty"`
+	Required    bool                         `json:"required,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse is the OpenAPI Response Object, Ref is used for refering to responses in the components
+type OpenAPIResponse struct {
+	Ref         string                       `json:"$ref,omitempty"`
+	Description string                       `json:"description,omitempty"`
+	Headers     map[string]*OpenAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIHeader is the OpenAPI Header Object
+type OpenAPIHeader struct {
+	Description string                 `json:"description,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// OpenAPIMediaType is the OpenAPI Media Type Object
+type OpenAPIMediaType struct {
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// OpenAPIComponents is the OpenAPI Components Object
+type OpenAPIComponents struct {
+	Schemas   map[string]map[string]interface{} `json:"schemas"`
+	Responses map[string]*OpenAPIResponse       `json:"responses"`
+}
+
+const jsonContentType = "application/json"
+
+func jsonContent(schema map[string]interface{}) map[string]*OpenAPIMediaType {
+	return map[string]*OpenAPIMediaType{jsonContentType: {Schema: schema}}
+}
+
+type openAPIBuilder struct {
+	schema   *Schema
+	document *OpenAPIDocument
+}
+
+func (b *openAPIBuilder) modelRef(uri string) map[string]interface{} {
+	if b.schema.Model(uri) == nil {
+		return modelURISchema(uri)
+	}
+
+	return map[string]interface{}{"$ref": "#/components/schemas/" + b.schema.Name(uri) + "URI"}
+}
+
+func (b *openAPIBuilder) operation(model *SchemaModel, id string, summary string, description string) *OpenAPIOperation {
+	name := b.schema.Name(model.Path)
+	return &OpenAPIOperation{
+		OperationID: name + "." + id,
+		Summary:     summary,
+		Description: description,
+		Tags:        []string{name},
+		Responses:   map[string]*OpenAPIResponse{"default": {Ref: "#/components/responses/Error"}},
+	}
+}
+
+func (b *openAPIBuilder) model(model *SchemaModel) {
+	name := b.schema.Name(model.Path)
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	idParameter := &OpenAPIParameter{Name: "id", In: "path", Required: true, Description: "Id of the " + model.Name, Schema: map[string]interface{}{"type": "string"}}
+
+	components := b.document.Components.Schemas
+	components[name] = objectSchema(model.Fields, b.modelRef)
+	if model.Doc != "" {
+		components[name]["description"] = model.Doc
+	}
+	components[name+"URI"] = modelURISchema(model.Path)
+	components[name+"URI"]["description"] = "URI of a " + model.Name
+
+	allowed := map[string]bool{"LIST": true, "CREATE": true, "GET": true, "UPDATE": true, "DELETE": true, "CALL": true}
+	for _, method := range model.NotAllowedMethods {
+		allowed[method] = false
+	}
+
+	modelItem := &OpenAPIPathItem{Summary: model.Name, AdditionalOperations: map[string]*OpenAPIOperation{}}
+	objectItem := &OpenAPIPathItem{Summary: model.Name, Parameters: []*OpenAPIParameter{idParameter}, AdditionalOperations: map[string]*OpenAPIOperation{}}
+
+	if allowed["LIST"] {
+		operation := b.operation(model, "list", "List "+model.Name, "")
+		operation.Parameters = []*OpenAPIParameter{
+			{Name: "Position", In: "header", Schema: map[string]interface{}{"type": "integer", "minimum": 0}},
+			{Name: "Count", In: "header", Schema: map[string]interface{}{"type": "integer", "minimum": 0}},
+		}
+		if len(model.ListFilters) > 0 {
+			filterNames := []string{}
+			filterSchemas := []interface{}{}
+			for _, filterName := range sortedKeys(model.ListFilters, nil) {
+				filterNames = append(filterNames, filterName)
+				filterSchema := objectSchema(model.ListFilters[filterName], b.modelRef)
+				filterSchema["title"] = filterName
+				filterSchemas = append(filterSchemas, filterSchema)
+			}
+			operation.Parameters = append(operation.Parameters, &OpenAPIParameter{Name: "Filter", In: "header", Description: "name of the filter, the filter values are the body", Schema: map[string]interface{}{"type": "string", "enum": filterNames}})
+			operation.RequestBody = &OpenAPIRequestBody{Description: "filter values", Content: jsonContent(map[string]interface{}{"oneOf": filterSchemas})}
+		}
+		countHeader := map[string]interface{}{"type": "integer"}
+		operation.Responses["200"] = &OpenAPIResponse{
+			Description: "URIs of the " + model.Name + "s",
+			Headers:     map[string]*OpenAPIHeader{"Position": {Schema: countHeader}, "Count": {Schema: countHeader}, "Total": {Schema: countHeader}},
+			Content:     jsonContent(map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/" + name + "URI"}}),
+		}
+		modelItem.AdditionalOperations["LIST"] = operation
+	}
+
+	if allowed["CREATE"] {
+		operation := b.operation(model, "create", "Create a "+model.Name, "")
+		operation.RequestBody = &OpenAPIRequestBody{Required: true, Content: jsonContent(ref)}
+		operation.Responses["201"] = &OpenAPIResponse{
+			Description: "the created " + model.Name,
+			Headers:     map[string]*OpenAPIHeader{"Object-Id": {Description: "URI of the created " + model.Name, Schema: map[string]interface{}{"$ref": "#/components/schemas/" + name + "URI"}}},
+			Content:     jsonContent(ref),
+		}
+		modelItem.AdditionalOperations["CREATE"] = operation
+	}
+
+	if allowed["GET"] {
+		operation := b.operation(model, "get", "Get a "+model.Name, "")
+		operation.Responses["200"] = &OpenAPIResponse{Description: "the " + model.Name, Content: jsonContent(ref)}
+		objectItem.Get = operation
+	}
+
+	if allowed["UPDATE"] {
+		operation := b.operation(model, "update", "Update a "+model.Name, "")
+		operation.RequestBody = &OpenAPIRequestBody{Required: true, Content: jsonContent(map[string]interface{}{"$ref": "#/components/schemas/" + name, "description": "the values to update"})}
+		operation.Responses["200"] = &OpenAPIResponse{Description: "the updated " + model.Name, Content: jsonContent(ref)}
+		objectItem.AdditionalOperations["UPDATE"] = operation
+	}
+
+	if allowed["DELETE"] {
+		operation := b.operation(model, "delete", "Delete a "+model.Name, "")
+		operation.Responses["200"] = &OpenAPIResponse{Description: "deleted"}
+		objectItem.Delete = operation
+	}
+
+	b.addPath(model.Path, modelItem)
+	b.addPath(model.Path+":{id}:", objectItem)
+
+	if !allowed["CALL"] {
+		return
+	}
+
+	for _, action := range model.Actions {
+		operation := b.operation(model, "call."+action.Name, action.Name, action.Doc)
+		operation.RequestBody = &OpenAPIRequestBody{Required: true, Content: jsonContent(objectSchema(action.Paramaters, b.modelRef))}
+		response := &OpenAPIResponse{Description: "result of " + action.Name}
+		if action.ReturnType != nil && action.ReturnType.Type != "" {
+			response.Content = jsonContent(fieldSchema(action.ReturnType.FieldParamater, b.modelRef))
+		}
+		operation.Responses["200"] = response
+
+		item := &OpenAPIPathItem{Summary: action.Name, AdditionalOperations: map[string]*OpenAPIOperation{"CALL": operation}}
+		if action.Static {
+			b.addPath(model.Path+"("+action.Name+")", item)
+		} else {
+			item.Parameters = []*OpenAPIParameter{idParameter}
+			b.addPath(model.Path+":{id}:("+action.Name+")", item)
+		}
+	}
+}
+
+func (b *openAPIBuilder) addPath(path string, item *OpenAPIPathItem) {
+	if item.Get == nil && item.Delete == nil && len(item.AdditionalOperations) == 0 {
+		return
+	}
+
+	if len(item.AdditionalOperations) == 0 {
+		item.AdditionalOperations = nil
+	} else if b.document.OpenAPI != OpenAPIVersion32 {
+		item.CInPOperations = item.AdditionalOperations
+		item.AdditionalOperations = nil
+	}
+
+	b.document.Paths[path] = item
+}
+
+// NewOpenAPI builds a OpenAPIVersion document from the schema, each Model gets a schema in the components and
+// operations for its allowed methods and actions.  serverURL is optional.
+func NewOpenAPI(schema *Schema, title string, serverURL string) *OpenAPIDocument {
+	document, _ := NewOpenAPIVersion(schema, title, serverURL, OpenAPIVersion)
+
+	return document
+}
+
+// NewOpenAPIVersion is NewOpenAPI for version, which is OpenAPIVersion or OpenAPIVersion32
+func NewOpenAPIVersion(schema *Schema, title string, serverURL string, version string) (*OpenAPIDocument, error) {
+	if version != OpenAPIVersion && version != OpenAPIVersion32 {
+		return nil, fmt.Errorf("unsupported OpenAPI version '%s', expected '%s' or '%s'", version, OpenAPIVersion, OpenAPIVersion32)
+	}
+
+	document := &OpenAPIDocument{
+		OpenAPI: version,
+		Info:    OpenAPIInfo{Title: title, Description: schema.Root.Doc, Version: schema.Root.APIVersion},
+		Paths:   map[string]*OpenAPIPathItem{},
+		Components: OpenAPIComponents{
+			Schemas: map[string]map[string]interface{}{
+				"Error": {
+					"type":       "object",
+					"properties": map[string]interface{}{"message": map[string]interface{}{"type": "string"}, "trace": map[string]interface{}{}},
+				},
+			},
+			Responses: map[string]*OpenAPIResponse{
+				"Error": {Description: "Invalid Request (400), Invalid Session (401), Not Authorized (403), Not Found (404) or Server Error (500)", Content: jsonContent(map[string]interface{}{"$ref": "#/components/schemas/Error"})},
+			},
+		},
+	}
+
+	if serverURL != "" {
+		document.Servers = []OpenAPIServer{{URL: serverURL}}
+	}
+
+	builder := &openAPIBuilder{schema: schema, document: document}
+	for _, model := range schema.Models() {
+		builder.model(model)
+	}
+
+	return document, nil
+}
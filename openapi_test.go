@@ -0,0 +1,126 @@
+package cinp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	schema := crawlTestAPI(t, testAPI())
+
+	document, err := NewOpenAPIVersion(schema, "Test API", "http://host", OpenAPIVersion32)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if document.OpenAPI != OpenAPIVersion32 || document.Info.Title != "Test API" || document.Info.Version != "1.2" || document.Servers[0].URL != "http://host" {
+		t.Errorf("Document header wrong, got '%+v'", document)
+		t.FailNow()
+	}
+
+	paths := sortedKeys(document.Paths, nil)
+	expectedPaths := []string{
+		"/api/v1/Building/Room",
+		"/api/v1/Building/Room:{id}:",
+		"/api/v1/Building/Room:{id}:(move)",
+		"/api/v1/Building/Site",
+		"/api/v1/Building/Site(summary)",
+		"/api/v1/Building/Site:{id}:",
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("Expected paths '%s' got '%s'", expectedPaths, paths)
+		t.FailNow()
+	}
+
+	site := document.Paths["/api/v1/Building/Site:{id}:"]
+	if site.Get == nil || site.Delete == nil || site.AdditionalOperations["UPDATE"] == nil || site.Get.OperationID != "Building.Site.get" {
+		t.Errorf("Site operations wrong, got '%+v'", site)
+		t.FailNow()
+	}
+
+	room := document.Paths["/api/v1/Building/Room:{id}:"]
+	if room.Delete != nil {
+		t.Errorf("Room DELETE is not allowed")
+		t.FailNow()
+	}
+
+	list := document.Paths["/api/v1/Building/Room"].AdditionalOperations["LIST"]
+	if list == nil || list.RequestBody == nil || !reflect.DeepEqual(list.Parameters[2].Schema["enum"], []string{"site"}) {
+		t.Errorf("Room LIST wrong, got '%+v'", list)
+		t.FailNow()
+	}
+
+	roomSchema := document.Components.Schemas["Building.Room"]
+	properties := roomSchema["properties"].(map[string]interface{})
+	if !reflect.DeepEqual(properties["site"], map[string]interface{}{"$ref": "#/components/schemas/Building.SiteURI"}) {
+		t.Errorf("Room.site wrong, got '%v'", properties["site"])
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(properties["kind"], map[string]interface{}{"type": "string", "maxLength": 10, "enum": []interface{}{"office", "lab"}}) {
+		t.Errorf("Room.kind wrong, got '%v'", properties["kind"])
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(roomSchema["required"], []string{"name", "site"}) {
+		t.Errorf("Room required wrong, got '%v'", roomSchema["required"])
+		t.FailNow()
+	}
+	if _, ok := document.Components.Schemas["Building.SiteURI"]; !ok {
+		t.Errorf("Building.SiteURI missing")
+		t.FailNow()
+	}
+
+	move := document.Paths["/api/v1/Building/Room:{id}:(move)"].AdditionalOperations["CALL"]
+	if move == nil || !reflect.DeepEqual(move.Responses["200"].Content[jsonContentType].Schema, map[string]interface{}{"type": "boolean"}) {
+		t.Errorf("Room(move) wrong, got '%+v'", move)
+		t.FailNow()
+	}
+
+	if _, err := json.Marshal(document); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+}
+
+func TestOpenAPIVersion(t *testing.T) {
+	schema := crawlTestAPI(t, testAPI())
+
+	document := NewOpenAPI(schema, "Test API", "")
+	if document.OpenAPI != OpenAPIVersion || document.Servers != nil {
+		t.Errorf("Document header wrong, got '%+v'", document)
+		t.FailNow()
+	}
+
+	for path, item := range document.Paths {
+		if item.AdditionalOperations != nil {
+			t.Errorf("Unexpected additionalOperations for '%s'", path)
+			t.FailNow()
+		}
+	}
+
+	site := document.Paths["/api/v1/Building/Site:{id}:"]
+	if site.Get == nil || site.Delete == nil || site.CInPOperations["UPDATE"] == nil {
+		t.Errorf("Site operations wrong, got '%+v'", site)
+		t.FailNow()
+	}
+
+	buff, err := json.Marshal(document.Paths["/api/v1/Building/Site"])
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(buff, &values); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, ok := values["x-cinp-operations"].(map[string]interface{})["LIST"]; !ok {
+		t.Errorf("LIST missing from x-cinp-operations, got '%s'", buff)
+		t.FailNow()
+	}
+
+	if _, err := NewOpenAPIVersion(schema, "Test API", "", "2.0"); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,153 @@
+package cinp
+
+// ParsedURI is a parsed CInP URI, build with URI.Parse.  The methods that change the URI return a modified copy,
+// the original is not changed.
+type ParsedURI struct {
+	Namespace []string
+	Model     string
+	Ids       []string // nil when the URI has no ids
+	Action    string
+	IsMulti   bool // more than one id
+	uri       *URI
+}
+
+// Parse parses uri, String returns exactly uri
+func (u *URI) Parse(uri string) (ParsedURI, error) {
+	ns, model, action, ids, multi, err := u.Split(uri)
+	if err != nil {
+		return ParsedURI{}, err
+	}
+
+	return ParsedURI{Namespace: ns, Model: model, Ids: ids, Action: action, IsMulti: multi, uri: u}, nil
+}
+
+// Build builds the URI, see URI.Build
+func (p ParsedURI) Build() (string, error) {
+	return p.uri.Build(p.Namespace, p.Model, p.Action, p.Ids)
+}
+
+// String builds the URI, "" if it can not be built, use Build to get the error
+func (p ParsedURI) String() string {
+	result, err := p.Build()
+	if err != nil {
+		return ""
+	}
+
+	return result
+}
+
+// IsNamespace returns true if the URI is of a namespace
+func (p ParsedURI) IsNamespace() bool {
+	return p.Model == ""
+}
+
+func (p ParsedURI) copy() ParsedURI {
+	result := p
+	if p.Namespace != nil {
+		result.Namespace = append([]string{}, p.Namespace...)
+	}
+	if p.Ids != nil {
+		result.Ids = append([]string{}, p.Ids...)
+	}
+
+	return result
+}
+
+// WithIds returns the URI with the ids replaced, nil removes the ids
+func (p ParsedURI) WithIds(ids ...string) ParsedURI {
+	result := p.copy()
+	result.Ids = nil
+	if ids != nil {
+		result.Ids = append([]string{}, ids...)
+	}
+	result.IsMulti = len(result.Ids) > 1
+
+	return result
+}
+
+// AddIds returns the URI with the ids added to the end, ids that are already in the URI are not added again
+func (p ParsedURI) AddIds(ids ...string) ParsedURI {
+	result := p.copy()
+	for _, id := range ids {
+		found := false
+		for _, existing := range result.Ids {
+			if existing == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.Ids = append(result.Ids, id)
+		}
+	}
+	result.IsMulti = len(result.Ids) > 1
+
+	return result
+}
+
+// RemoveIds returns the URI with out the ids, if all the ids are removed the URI has no ids
+func (p ParsedURI) RemoveIds(ids ...string) ParsedURI {
+	remove := map[string]bool{}
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	result := p.copy()
+	result.Ids = nil
+	for _, id := range p.Ids {
+		if !remove[id] {
+			result.Ids = append(result.Ids, id)
+		}
+	}
+	result.IsMulti = len(result.Ids) > 1
+
+	return result
+}
+
+// WithAction returns the URI with the action set, "" removes the action
+func (p ParsedURI) WithAction(action string) ParsedURI {
+	result := p.copy()
+	result.Action = action
+
+	return result
+}
+
+// ParentModel returns the model of a object or action URI, with out the ids and action.  ok is false if the URI
+// is a namespace.
+func (p ParsedURI) ParentModel() (ParsedURI, bool) {
+	if p.Model == "" {
+		return p, false
+	}
+
+	result := p.copy()
+	result.Ids = nil
+	result.IsMulti = false
+	result.Action = ""
+
+	return result, true
+}
+
+// ParentNamespace returns the namespace the model is in, or for a namespace, the namespace it is in.  ok is false
+// for the root namespace.
+func (p ParsedURI) ParentNamespace() (ParsedURI, bool) {
+	result := p.copy()
+	result.Ids = nil
+	result.IsMulti = false
+	result.Action = ""
+
+	if p.Model != "" {
+		result.Model = ""
+		return result, true
+	}
+
+	if len(p.Namespace) == 0 {
+		return p, false
+	}
+
+	result.Namespace = result.Namespace[:len(result.Namespace)-1]
+	if len(result.Namespace) == 0 {
+		result.Namespace = nil
+	}
+
+	return result, true
+}
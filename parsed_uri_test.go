@@ -0,0 +1,92 @@
+package cinp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsedURI(t *testing.T) {
+	u, err := NewURI("/api/v1/")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	for _, v := range []string{"/api/v1/", "/api/v1/ns/", "/api/v1/ns/ns2/", "/api/v1/ns/model", "/api/v1/ns/model::", "/api/v1/ns/model:ghj:", "/api/v1/ns/model:ghj:dsf:sfe:", "/api/v1/ns/model(action)", "/api/v1/ns/model:sdf:(action)", "/api/v1/ns/model:sdf:eed:(action)", "/api/v1/model"} {
+		p, err := u.Parse(v)
+		if err != nil {
+			t.Errorf("Unexpected error '%s' for '%s'", err, v)
+			t.FailNow()
+		}
+		if p.String() != v {
+			t.Errorf("Expected '%s' got '%s'", v, p.String())
+			t.FailNow()
+		}
+	}
+
+	for _, v := range []string{"/api/v2/", "/api/v1/ns/:1:", "/api/v1/(action)", "/api/v1/ns/model:1"} {
+		if _, err := u.Parse(v); err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+	}
+
+	p, err := u.Parse("/api/v1/ns/ns2/model:a:b:(action)")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(p.Namespace, []string{"ns", "ns2"}) || p.Model != "model" || !reflect.DeepEqual(p.Ids, []string{"a", "b"}) || p.Action != "action" || !p.IsMulti || p.IsNamespace() {
+		t.Errorf("Wrong parse '%+v'", p)
+		t.FailNow()
+	}
+
+	tests := []struct {
+		result   ParsedURI
+		expected string
+		multi    bool
+	}{
+		{p.WithIds("c"), "/api/v1/ns/ns2/model:c:(action)", false},
+		{p.WithIds(), "/api/v1/ns/ns2/model(action)", false},
+		{p.AddIds("b", "c"), "/api/v1/ns/ns2/model:a:b:c:(action)", true},
+		{p.RemoveIds("a"), "/api/v1/ns/ns2/model:b:(action)", false},
+		{p.RemoveIds("a", "b"), "/api/v1/ns/ns2/model(action)", false},
+		{p.WithAction("other"), "/api/v1/ns/ns2/model:a:b:(other)", true},
+		{p.WithAction(""), "/api/v1/ns/ns2/model:a:b:", true},
+		{p.WithIds().WithAction("").AddIds("x"), "/api/v1/ns/ns2/model:x:", false},
+	}
+	for _, test := range tests {
+		if test.result.String() != test.expected || test.result.IsMulti != test.multi {
+			t.Errorf("Expected '%s' (%t) got '%s' (%t)", test.expected, test.multi, test.result.String(), test.result.IsMulti)
+			t.FailNow()
+		}
+	}
+	if p.String() != "/api/v1/ns/ns2/model:a:b:(action)" {
+		t.Errorf("Original changed '%s'", p.String())
+		t.FailNow()
+	}
+
+	model, ok := p.ParentModel()
+	if !ok || model.String() != "/api/v1/ns/ns2/model" || model.IsMulti {
+		t.Errorf("Wrong parent model '%s'", model.String())
+		t.FailNow()
+	}
+
+	expected := []string{"/api/v1/ns/ns2/", "/api/v1/ns/", "/api/v1/"}
+	current := p
+	for _, v := range expected {
+		current, ok = current.ParentNamespace()
+		if !ok || current.String() != v || !current.IsNamespace() {
+			t.Errorf("Expected parent '%s' got '%s'", v, current.String())
+			t.FailNow()
+		}
+	}
+	if _, ok = current.ParentNamespace(); ok {
+		t.Errorf("root has a parent")
+		t.FailNow()
+	}
+	if _, ok = current.ParentModel(); ok {
+		t.Errorf("namespace has a model")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,234 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ModelRef is for Model fields of registered types, it holds the URI of the object and resolves it to the
+// object when asked, through a Resolver.  A ModelRef is not safe for concurrent use.
+type ModelRef struct {
+	URI    string
+	object Object
+}
+
+// NewModelRef returns a ModelRef for the URI
+func NewModelRef(uri string) ModelRef {
+	return ModelRef{URI: uri}
+}
+
+// MarshalJSON encodes the URI, or null if there is no URI
+func (r ModelRef) MarshalJSON() ([]byte, error) {
+	if r.URI == "" {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(r.URI)
+}
+
+// UnmarshalJSON decodes the URI, null is no URI
+func (r *ModelRef) UnmarshalJSON(data []byte) error {
+	var uri *string
+	if err := json.Unmarshal(data, &uri); err != nil {
+		return err
+	}
+
+	r.object = nil
+	r.URI = ""
+	if uri != nil {
+		r.URI = *uri
+	}
+
+	return nil
+}
+
+// Resolved returns the object if the reference has already been resolved
+func (r *ModelRef) Resolved() (Object, bool) {
+	return r.object, r.object != nil
+}
+
+// Resolve returns the object the reference is to, getting it with the resolver the first time.  Returns nil
+// if there is no URI.
+func (r *ModelRef) Resolve(ctx context.Context, resolver *Resolver) (Object, error) {
+	if r.object != nil || r.URI == "" {
+		return r.object, nil
+	}
+
+	object, err := resolver.Get(ctx, r.URI)
+	if err != nil {
+		return nil, err
+	}
+	r.object = object
+
+	return object, nil
+}
+
+// Resolver resolves ModelRefs, caching the objects by URI so each object is only fetched once.  The cache is
+// never expired, make a new Resolver for each request/unit of work.  A Resolver is safe for concurrent use.
+type Resolver struct {
+	cinp  *CInP
+	mutex sync.Mutex
+	cache map[string]Object
+}
+
+// NewResolver returns a Resolver with a empty cache
+func (cinp *CInP) NewResolver() *Resolver {
+	return &Resolver{cinp: cinp, cache: map[string]Object{}}
+}
+
+// Get returns the object at uri, from the cache if it has already been fetched
+func (r *Resolver) Get(ctx context.Context, uri string) (Object, error) {
+	r.mutex.Lock()
+	object, ok := r.cache[uri]
+	r.mutex.Unlock()
+	if ok {
+		return object, nil
+	}
+
+	result, err := r.cinp.Get(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	r.cache[uri] = *result
+	r.mutex.Unlock()
+
+	return *result, nil
+}
+
+// Prefetch resolves all the ModelRefs in valueList, which can be pointers to ModelRefs and objects, and slices of
+// them.  ModelRefs in struct fields (including nested structs, slices and maps) are found, a ModelRef that can not
+// be set, ie in a struct passed by value or a map value, is a error.  The objects that are not already in the
+// cache are fetched with multi-object GETs, in batches of up to the namespace's multi-uri-max.
+func (r *Resolver) Prefetch(ctx context.Context, valueList ...interface{}) error {
+	refList := []*ModelRef{}
+	visited := map[refVisit]bool{}
+	for _, value := range valueList {
+		var err error
+		if refList, err = collectModelRefs(reflect.ValueOf(value), refList, visited); err != nil {
+			return err
+		}
+	}
+
+	r.mutex.Lock()
+	uriList := []string{}
+	seen := map[string]bool{}
+	for _, ref := range refList {
+		if ref.URI == "" || seen[ref.URI] {
+			continue
+		}
+		seen[ref.URI] = true
+		if _, ok := r.cache[ref.URI]; !ok {
+			uriList = append(uriList, ref.URI)
+		}
+	}
+	r.mutex.Unlock()
+
+	if len(uriList) > 0 {
+		r.cinp.log.Debug("Prefetch", "count", len(uriList))
+
+		batchList, err := r.cinp.uriBatches(ctx, uriList)
+		if err != nil {
+			return err
+		}
+
+		for _, batch := range batchList {
+			objects, err := r.cinp.GetMulti(ctx, batch.uri)
+			if err != nil {
+				return err
+			}
+
+			r.mutex.Lock()
+			for uri, object := range objects {
+				r.cache[uri] = object
+			}
+			r.mutex.Unlock()
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, ref := range refList {
+		if ref.URI == "" {
+			continue
+		}
+
+		object, ok := r.cache[ref.URI]
+		if !ok {
+			return fmt.Errorf("'%s' was not returned", ref.URI)
+		}
+		ref.object = object
+	}
+
+	return nil
+}
+
+var modelRefType = reflect.TypeOf(ModelRef{})
+
+// refVisit is a pointer or map collectModelRefs has been in, the type is needed as a struct and it's first field
+// have the same address
+type refVisit struct {
+	pointer uintptr
+	typ     reflect.Type
+}
+
+// collectModelRefs appends the ModelRefs in value to result, ModelRefs that are not addressable are a error.
+// Pointers and maps already in visited are skipped, so self referencing values end.
+func collectModelRefs(value reflect.Value, result []*ModelRef, visited map[refVisit]bool) ([]*ModelRef, error) {
+	var err error
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map:
+		if value.IsNil() {
+			return result, nil
+		}
+		visit := refVisit{pointer: value.Pointer(), typ: value.Type()}
+		if visited[visit] {
+			return result, nil
+		}
+		visited[visit] = true
+
+		if value.Kind() == reflect.Pointer {
+			return collectModelRefs(value.Elem(), result, visited)
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			if result, err = collectModelRefs(iter.Value(), result, visited); err != nil {
+				return nil, err
+			}
+		}
+
+	case reflect.Interface:
+		if !value.IsNil() {
+			return collectModelRefs(value.Elem(), result, visited)
+		}
+
+	case reflect.Struct:
+		if value.Type() == modelRefType {
+			if !value.CanAddr() {
+				return nil, fmt.Errorf("ModelRef '%s' can not be set, use a pointer to it's object", value.Interface().(ModelRef).URI)
+			}
+			return append(result, value.Addr().Interface().(*ModelRef)), nil
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).IsExported() {
+				if result, err = collectModelRefs(value.Field(i), result, visited); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if result, err = collectModelRefs(value.Index(i), result, visited); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,187 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testRefRoom struct {
+	BaseObject
+	Name  string     `json:"name"`
+	Site  ModelRef   `json:"site"`
+	Other *ModelRef  `json:"other"`
+	Many  []ModelRef `json:"many"`
+}
+
+type testRefNode struct {
+	Ref  ModelRef
+	Next *testRefNode
+}
+
+func TestModelRefJSON(t *testing.T) {
+	room := &testRefRoom{}
+	if err := json.Unmarshal([]byte(`{"name": "a", "site": "/api/v1/Building/Site:1:", "other": null, "many": ["/api/v1/Building/Site:2:"]}`), room); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if room.Site.URI != "/api/v1/Building/Site:1:" || room.Other != nil || len(room.Many) != 1 || room.Many[0].URI != "/api/v1/Building/Site:2:" {
+		t.Errorf("Wrong room '%+v'", room)
+		t.FailNow()
+	}
+
+	room.Site = ModelRef{}
+	buff, err := json.Marshal(room)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if string(buff) != `{"name":"a","site":null,"other":null,"many":["/api/v1/Building/Site:2:"]}` {
+		t.Errorf("Wrong JSON '%s'", buff)
+		t.FailNow()
+	}
+
+	if err := json.Unmarshal([]byte(`{"site": 5}`), room); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestResolver(t *testing.T) {
+	u, err := NewURI("/api/v1/")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	var mutex sync.Mutex
+	var requests []string
+	server := newTestAPIServer(testAPI(), func(rw http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		requests = append(requests, req.Method+" "+req.URL.Path+" "+req.Header.Get("Multi-Object"))
+		mutex.Unlock()
+
+		_, _, _, ids, _, _ := u.Split(req.URL.Path)
+		if req.Header.Get("Multi-Object") != "True" {
+			json.NewEncoder(rw).Encode(map[string]interface{}{"name": "site " + ids[0]})
+			return
+		}
+
+		result := map[string]interface{}{}
+		for _, id := range ids {
+			if id != "404" {
+				result["/api/v1/Building/Site:"+id+":"] = map[string]interface{}{"name": "site " + id}
+			}
+		}
+		rw.Header().Set("Multi-Object", "True")
+		json.NewEncoder(rw).Encode(result)
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	ctx := context.TODO()
+	resolver := c.NewResolver()
+
+	ref := NewModelRef("/api/v1/Building/Site:1:")
+	if _, ok := ref.Resolved(); ok {
+		t.Errorf("Resolved before resolving")
+		t.FailNow()
+	}
+	for i := 0; i < 2; i++ {
+		object, err := ref.Resolve(ctx, resolver)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if object.(*MappedObject).Data["name"] != "site 1" {
+			t.Errorf("Wrong object '%+v'", object)
+			t.FailNow()
+		}
+	}
+	again := NewModelRef("/api/v1/Building/Site:1:")
+	if _, err = again.Resolve(ctx, resolver); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(requests, []string{"GET /api/v1/Building/Site:1: "}) {
+		t.Errorf("Expected one GET got '%v'", requests)
+		t.FailNow()
+	}
+
+	if object, err := (&ModelRef{}).Resolve(ctx, resolver); object != nil || err != nil {
+		t.Errorf("Expected nothing for a empty ref")
+		t.FailNow()
+	}
+
+	other := NewModelRef("/api/v1/Building/Site:5:")
+	page := []*testRefRoom{
+		{Site: NewModelRef("/api/v1/Building/Site:1:"), Many: []ModelRef{NewModelRef("/api/v1/Building/Site:2:"), NewModelRef("/api/v1/Building/Site:3:")}},
+		{Site: NewModelRef("/api/v1/Building/Site:2:"), Other: &other},
+		{Site: NewModelRef("/api/v1/Building/Site:4:")},
+	}
+	requests = nil
+	if err = resolver.Prefetch(ctx, page); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	sort.Strings(requests)
+	if !reflect.DeepEqual(requests, []string{"GET /api/v1/Building/Site:2:3: True", "GET /api/v1/Building/Site:5:4: True"}) {
+		t.Errorf("Wrong requests '%v'", requests)
+		t.FailNow()
+	}
+
+	for _, ref := range []*ModelRef{&page[0].Many[1], page[1].Other, &page[2].Site} {
+		object, ok := ref.Resolved()
+		if !ok || object.GetURI() != ref.URI || !strings.HasSuffix(ref.URI, ":"+strings.TrimPrefix(object.(*MappedObject).Data["name"].(string), "site ")+":") {
+			t.Errorf("Wrong object for '%s' '%+v'", ref.URI, object)
+			t.FailNow()
+		}
+	}
+
+	requests = nil
+	if err = resolver.Prefetch(ctx, page[0], &page[1].Site); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if len(requests) != 0 {
+		t.Errorf("Expected cached got '%v'", requests)
+		t.FailNow()
+	}
+
+	if err = resolver.Prefetch(ctx, &testRefRoom{Site: NewModelRef("/api/v1/Building/Site:404:")}); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	// refs that can not be set are a error
+	for _, value := range []interface{}{testRefRoom{Site: NewModelRef("/api/v1/Building/Site:1:")}, map[string]testRefRoom{"a": {}}} {
+		if err = resolver.Prefetch(ctx, value); err == nil {
+			t.Errorf("error missing for '%+v'", value)
+			t.FailNow()
+		}
+	}
+
+	// self referencing values end
+	node := &testRefNode{Ref: NewModelRef("/api/v1/Building/Site:1:")}
+	node.Next = node
+	loop := map[string]interface{}{"node": node}
+	loop["loop"] = loop
+	requests = nil
+	if err = resolver.Prefetch(ctx, loop); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, ok := node.Ref.Resolved(); !ok || len(requests) != 0 {
+		t.Errorf("Expected resolved from the cache got '%v'", requests)
+		t.FailNow()
+	}
+}
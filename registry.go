@@ -0,0 +1,189 @@
+package cinp
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// UnregisteredType is the error for a object of a model with no registered type, when strict types are on
+type UnregisteredType struct {
+	URI string
+}
+
+func (e *UnregisteredType) Error() string {
+	return fmt.Sprintf("no type registered for '%s'", e.URI)
+}
+
+// TypeRegistration is a type to register for a model URI or pattern, see RegisterTypePattern
+type TypeRegistration struct {
+	URI  string
+	Type reflect.Type
+}
+
+type typePattern struct {
+	pattern    string
+	prefix     string // for patterns ending in "/**", the namespace the models are under
+	objectType reflect.Type
+}
+
+// typeRegistry is the registered types of a mount, exact model URIs are checked before the patterns, the
+// patterns are checked in the order they where registered
+type typeRegistry struct {
+	exact    map[string]reflect.Type
+	patterns []typePattern
+}
+
+func newTypeRegistry() *typeRegistry {
+	return &typeRegistry{exact: map[string]reflect.Type{}}
+}
+
+func (r *typeRegistry) lookup(modelURI string) (reflect.Type, bool) {
+	if objectType, ok := r.exact[modelURI]; ok {
+		return objectType, true
+	}
+
+	for _, item := range r.patterns {
+		if item.prefix != "" {
+			if strings.HasPrefix(modelURI, item.prefix) {
+				return item.objectType, true
+			}
+			continue
+		}
+		if ok, _ := path.Match(item.pattern, modelURI); ok {
+			return item.objectType, true
+		}
+	}
+
+	return nil, false
+}
+
+func checkObjectType(objectType reflect.Type) error {
+	if objectType == nil {
+		return fmt.Errorf("type is nil")
+	}
+
+	if _, ok := reflect.New(objectType).Interface().(Object); !ok {
+		return fmt.Errorf("%v does not implement Object", objectType)
+	}
+
+	return nil
+}
+
+// RegisterTypePattern registers the type to use for the models with URIs matching the pattern.  The pattern is
+// matched with path.Match, ie "/api/v1/Building/*" is the models in the Building namespace.  A pattern ending in
+// "/**" is all the models in the namespace and it's sub-namespaces, ie "/api/v1/Building/**".  Types registered
+// with RegisterType take precedence, then the patterns in the order they where registered.
+func (cinp *CInP) RegisterTypePattern(pattern string, objectType reflect.Type) error {
+	if err := checkObjectType(objectType); err != nil {
+		return err
+	}
+
+	item := typePattern{pattern: pattern, objectType: objectType}
+	if strings.HasSuffix(pattern, "/**") {
+		item.prefix = strings.TrimSuffix(pattern, "**")
+		if strings.ContainsAny(item.prefix, "*?[\\") {
+			return fmt.Errorf("pattern '%s' can not have wildcards before the '/**'", pattern)
+		}
+	} else if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+	}
+
+	cinp.log.Debug("Register Type Pattern", "pattern", pattern, "type", objectType)
+
+	registry := cinp.mountFor(pattern).typeRegistry
+	registry.patterns = append(registry.patterns, item)
+
+	return nil
+}
+
+// RegisterTypes registers the types, URIs with wildcards are registered with RegisterTypePattern
+func (cinp *CInP) RegisterTypes(typeList []TypeRegistration) error {
+	for _, item := range typeList {
+		if strings.ContainsAny(item.URI, "*?[") {
+			if err := cinp.RegisterTypePattern(item.URI, item.Type); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := checkObjectType(item.Type); err != nil {
+			return err
+		}
+		cinp.RegisterType(item.URI, item.Type)
+	}
+
+	return nil
+}
+
+var generatedTypesMutex sync.Mutex
+var generatedTypes []TypeRegistration
+
+// AddGeneratedTypes adds types to be registered by RegisterGeneratedTypes, for generated code to call from it's
+// init, so the client does not have to know the generated types
+func AddGeneratedTypes(typeList ...TypeRegistration) {
+	generatedTypesMutex.Lock()
+	defer generatedTypesMutex.Unlock()
+
+	generatedTypes = append(generatedTypes, typeList...)
+}
+
+// RegisterGeneratedTypes registers the types added with AddGeneratedTypes, see RegisterTypes
+func (cinp *CInP) RegisterGeneratedTypes() error {
+	generatedTypesMutex.Lock()
+	typeList := append([]TypeRegistration{}, generatedTypes...)
+	generatedTypesMutex.Unlock()
+
+	return cinp.RegisterTypes(typeList)
+}
+
+// SetStrictTypes when strict is true, getting a object of a model with no registered type is a
+// UnregisteredType error, instead of the object being a MappedObject.  Register MappedObjectType for models
+// that should still be MappedObjects.
+func (cinp *CInP) SetStrictTypes(strict bool) {
+	cinp.log.Debug("Set Strict Types", "strict", strict)
+	cinp.strictTypes = strict
+}
+
+// modelURI returns the URI of the model of a object URI, with out the ids and action
+func (cinp *CInP) modelURI(uri string) string {
+	u := cinp.uriFor(uri)
+	ns, model, _, _, _, err := u.Split(uri)
+	if err == nil {
+		if result, err := u.Build(ns, model, "", nil); err == nil {
+			return result
+		}
+	}
+
+	offset := strings.IndexAny(uri, ":(")
+	if offset != -1 {
+		uri = uri[:offset]
+	}
+
+	return uri
+}
+
+func (cinp *CInP) objectType(uri string) (reflect.Type, error) {
+	modelURI := cinp.modelURI(uri)
+
+	objectType, ok := cinp.mountFor(modelURI).typeRegistry.lookup(modelURI)
+	if !ok {
+		if cinp.strictTypes {
+			return nil, &UnregisteredType{URI: modelURI}
+		}
+		return MappedObjectType, nil
+	}
+
+	return objectType, nil
+}
+
+func (cinp *CInP) newObject(uri string) (Object, error) {
+	objectType, err := cinp.objectType(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return reflect.New(objectType).Interface().(Object), nil
+}
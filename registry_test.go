@@ -0,0 +1,132 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRegisterTypePattern(t *testing.T) {
+	c, err := NewCInP(getLogger(), "http://localhost", "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	siteType := reflect.TypeOf((*testSite)(nil)).Elem()
+	roomType := reflect.TypeOf((*testRoom)(nil)).Elem()
+
+	for _, v := range []string{"/api/v1/Build[ing/*", "/api/v1/*/**"} {
+		if err := c.RegisterTypePattern(v, siteType); err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+	}
+	if err := c.RegisterTypePattern("/api/v1/*", reflect.TypeOf("")); err == nil {
+		t.Errorf("error missing for non Object")
+		t.FailNow()
+	}
+
+	if err := c.RegisterTypes([]TypeRegistration{{URI: "/api/v1/Building/*", Type: roomType}, {URI: "/api/v1/Other/**", Type: siteType}}); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	c.RegisterType("/api/v1/Building/Site", siteType)
+
+	for uri, expected := range map[string]reflect.Type{
+		"/api/v1/Building/Site:1:":          siteType,
+		"/api/v1/Building/Room:1:2:":        roomType,
+		"/api/v1/Building/Room:a%3Ab:":      roomType,
+		"/api/v1/Building/Sub/Room:1:":      MappedObjectType,
+		"/api/v1/Other/Thing:1:":            siteType,
+		"/api/v1/Other/Deep/Thing:1:":       siteType,
+		"/api/v1/Other/Deep/Thing:1:(stop)": siteType,
+		"/api/v1/Auth/User:1:":              MappedObjectType,
+	} {
+		objectType, err := c.objectType(uri)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if objectType != expected {
+			t.Errorf("Expected '%v' for '%s' got '%v'", expected, uri, objectType)
+			t.FailNow()
+		}
+	}
+
+	c.SetStrictTypes(true)
+	_, err = c.objectType("/api/v1/Auth/User:1:")
+	var unregistered *UnregisteredType
+	if !errors.As(err, &unregistered) || unregistered.URI != "/api/v1/Auth/User" {
+		t.Errorf("Expected UnregisteredType got '%v'", err)
+		t.FailNow()
+	}
+
+	c.RegisterType("/api/v1/Auth/User", MappedObjectType)
+	if objectType, err := c.objectType("/api/v1/Auth/User:1:"); err != nil || objectType != MappedObjectType {
+		t.Errorf("Expected MappedObjectType got '%v' '%v'", objectType, err)
+		t.FailNow()
+	}
+}
+
+func TestGeneratedTypes(t *testing.T) {
+	siteType := reflect.TypeOf((*testSite)(nil)).Elem()
+
+	AddGeneratedTypes(TypeRegistration{URI: "/api/v1/Generated/Site", Type: siteType})
+	defer func() { generatedTypes = nil }()
+
+	c, err := NewCInP(getLogger(), "http://localhost", "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := c.RegisterGeneratedTypes(); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if objectType, err := c.objectType("/api/v1/Generated/Site:1:"); err != nil || objectType != siteType {
+		t.Errorf("Expected '%v' got '%v' '%v'", siteType, objectType, err)
+		t.FailNow()
+	}
+}
+
+func TestStrictTypesGet(t *testing.T) {
+	server := newTestAPIServer(testAPI(), func(rw http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(rw).Encode(map[string]interface{}{"name": "one"})
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	c.SetStrictTypes(true)
+
+	_, err = c.Get(context.TODO(), "/api/v1/Building/Site:1:")
+	var unregistered *UnregisteredType
+	if !errors.As(err, &unregistered) {
+		t.Errorf("Expected UnregisteredType got '%v'", err)
+		t.FailNow()
+	}
+
+	if err := c.RegisterTypePattern("/api/v1/Building/**", reflect.TypeOf((*testSite)(nil)).Elem()); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	object, err := c.Get(context.TODO(), "/api/v1/Building/Site:1:")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if site, ok := (*object).(*testSite); !ok || site.Name != "one" {
+		t.Errorf("Wrong object '%+v'", *object)
+		t.FailNow()
+	}
+}
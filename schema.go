@@ -0,0 +1,300 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// SchemaField is a Field or Paramater, Model is set for Model type fields when the model is in the Schema
+type SchemaField struct {
+	FieldParamater
+	Model *SchemaModel `json:"-"`
+}
+
+// SchemaAction is a crawled Action
+type SchemaAction struct {
+	Name       string         `json:"name"`
+	Doc        string         `json:"doc"`
+	Path       string         `json:"path"`
+	Static     bool           `json:"static"`
+	ReturnType *SchemaField   `json:"return-type"`
+	Paramaters []*SchemaField `json:"paramaters"`
+}
+
+// SchemaModel is a crawled Model
+type SchemaModel struct {
+	Name              string                    `json:"name"`
+	Doc               string                    `json:"doc"`
+	Path              string                    `json:"path"`
+	Constants         map[string]string         `json:"constants"`
+	Fields            []*SchemaField            `json:"fields"`
+	Actions           []*SchemaAction           `json:"actions"`
+	NotAllowedMethods []string                  `json:"not-allowed-methods"`
+	ListFilters       map[string][]*SchemaField `json:"list-filters"`
+}
+
+// SchemaNamespace is a crawled Namespace
+type SchemaNamespace struct {
+	Name        string             `json:"name"`
+	Doc         string             `json:"doc"`
+	Path        string             `json:"path"`
+	APIVersion  string             `json:"api-version"`
+	MultiURIMax int                `json:"multi-uri-max"`
+	Namespaces  []*SchemaNamespace `json:"namespaces"`
+	Models      []*SchemaModel     `json:"models"`
+}
+
+// Schema is the graph of namespaces, models and actions of an API
+type Schema struct {
+	Root   *SchemaNamespace        `json:"root"`
+	models map[string]*SchemaModel // by path
+}
+
+// Model returns the model with the path, nil if the model is not in the Schema
+func (s *Schema) Model(path string) *SchemaModel {
+	return s.models[path]
+}
+
+// Models returns all the models in the schema, in namespace tree order
+func (s *Schema) Models() []*SchemaModel {
+	result := []*SchemaModel{}
+	s.Walk(func(namespace *SchemaNamespace) {
+		result = append(result, namespace.Models...)
+	})
+
+	return result
+}
+
+// Name is the path of a namespace, model or action relative to the schema root, with "." as the seperator,
+// ie "/api/v1/Building/Room(move)" is "Building.Room.move"
+func (s *Schema) Name(path string) string {
+	name := strings.Trim(strings.TrimPrefix(path, s.Root.Path), "/")
+	if offset := strings.IndexByte(name, '('); offset != -1 {
+		name = name[:offset] + "/" + strings.TrimSuffix(name[offset+1:], ")")
+	}
+
+	return strings.ReplaceAll(name, "/", ".")
+}
+
+// Walk calls fn for each namespace, parents before children
+func (s *Schema) Walk(fn func(namespace *SchemaNamespace)) {
+	var walk func(namespace *SchemaNamespace)
+	walk = func(namespace *SchemaNamespace) {
+		fn(namespace)
+		for _, child := range namespace.Namespaces {
+			walk(child)
+		}
+	}
+
+	if s.Root != nil {
+		walk(s.Root)
+	}
+}
+
+// Save writes the schema as JSON, for loading with LoadSchema
+func (s *Schema) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(s)
+}
+
+// LoadSchema reads a schema saved with Save
+func LoadSchema(r io.Reader) (*Schema, error) {
+	result := &Schema{}
+	if err := json.NewDecoder(r).Decode(result); err != nil {
+		return nil, err
+	}
+
+	if result.Root == nil {
+		return nil, errors.New("schema does not have a root namespace")
+	}
+
+	result.link()
+
+	return result, nil
+}
+
+// link indexes the models and points Model type fields at their model
+func (s *Schema) link() {
+	s.models = map[string]*SchemaModel{}
+	for _, model := range s.Models() {
+		s.models[model.Path] = model
+	}
+
+	linkFields := func(fieldList []*SchemaField) {
+		for _, field := range fieldList {
+			if field != nil && field.Type == "Model" {
+				field.Model = s.models[field.URI]
+			}
+		}
+	}
+
+	for _, model := range s.models {
+		linkFields(model.Fields)
+		for _, filter := range model.ListFilters {
+			linkFields(filter)
+		}
+		for _, action := range model.Actions {
+			linkFields([]*SchemaField{action.ReturnType})
+			linkFields(action.Paramaters)
+		}
+	}
+}
+
+func newSchemaFields(fieldList []FieldParamater) []*SchemaField {
+	result := make([]*SchemaField, len(fieldList))
+	for i, field := range fieldList {
+		result[i] = &SchemaField{FieldParamater: field}
+	}
+
+	return result
+}
+
+type crawler struct {
+	cinp   *CInP
+	sem    chan struct{}
+	wg     sync.WaitGroup
+	mutex  sync.Mutex
+	err    error
+	cancel context.CancelFunc
+}
+
+func (c *crawler) fail(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		c.cancel()
+	}
+}
+
+func (c *crawler) describe(ctx context.Context, uri string, expectedType string) (*Describe, bool) {
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		c.fail(ctx.Err())
+		return nil, false
+	}
+	defer func() { <-c.sem }()
+
+	describe, describeType, err := c.cinp.Describe(ctx, uri)
+	if err != nil {
+		c.fail(fmt.Errorf("describe of '%s' failed: %w", uri, err))
+		return nil, false
+	}
+
+	if describeType != expectedType {
+		c.fail(fmt.Errorf("expected '%s' to be a '%s' got '%s'", uri, expectedType, describeType))
+		return nil, false
+	}
+
+	return describe, true
+}
+
+func (c *crawler) namespace(ctx context.Context, uri string, result *SchemaNamespace) {
+	defer c.wg.Done()
+
+	describe, ok := c.describe(ctx, uri, "Namespace")
+	if !ok {
+		return
+	}
+
+	result.Name = describe.Name
+	result.Doc = describe.Doc
+	result.Path = describe.Path
+	result.APIVersion = describe.APIVersion
+	result.MultiURIMax = describe.MultiURIMax
+	result.Namespaces = make([]*SchemaNamespace, len(describe.Namespaces))
+	result.Models = make([]*SchemaModel, len(describe.Models))
+
+	for i, child := range describe.Namespaces {
+		result.Namespaces[i] = &SchemaNamespace{}
+		c.wg.Add(1)
+		go c.namespace(ctx, child, result.Namespaces[i])
+	}
+
+	for i, child := range describe.Models {
+		result.Models[i] = &SchemaModel{}
+		c.wg.Add(1)
+		go c.model(ctx, child, result.Models[i])
+	}
+}
+
+func (c *crawler) model(ctx context.Context, uri string, result *SchemaModel) {
+	defer c.wg.Done()
+
+	describe, ok := c.describe(ctx, uri, "Model")
+	if !ok {
+		return
+	}
+
+	result.Name = describe.Name
+	result.Doc = describe.Doc
+	result.Path = describe.Path
+	result.Constants = describe.Constants
+	result.Fields = newSchemaFields(describe.Fields)
+	result.NotAllowedMethods = describe.NotAllowedMethods
+	result.ListFilters = map[string][]*SchemaField{}
+	for name, filter := range describe.ListFilters {
+		result.ListFilters[name] = newSchemaFields(filter)
+	}
+	result.Actions = make([]*SchemaAction, len(describe.Actions))
+
+	for i, child := range describe.Actions {
+		result.Actions[i] = &SchemaAction{}
+		c.wg.Add(1)
+		go c.action(ctx, child, result.Actions[i])
+	}
+}
+
+func (c *crawler) action(ctx context.Context, uri string, result *SchemaAction) {
+	defer c.wg.Done()
+
+	describe, ok := c.describe(ctx, uri, "Action")
+	if !ok {
+		return
+	}
+
+	result.Name = describe.Name
+	result.Doc = describe.Doc
+	result.Path = describe.Path
+	result.Static = describe.Static
+	result.ReturnType = &SchemaField{FieldParamater: describe.ReturnType}
+	result.Paramaters = newSchemaFields(describe.Paramaters)
+}
+
+// Crawl describes the namespace at uri and everything under it, making at most parallelism describe requests
+// at a time.  Model type fields are linked to their models, if they are in the crawled part of the API.
+func (cinp *CInP) Crawl(ctx context.Context, uri string, parallelism int) (*Schema, error) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	cinp.log.Info("CRAWL", "uri", uri, "parallelism", parallelism)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := &crawler{cinp: cinp, sem: make(chan struct{}, parallelism), cancel: cancel}
+	result := &Schema{Root: &SchemaNamespace{}}
+
+	c.wg.Add(1)
+	go c.namespace(ctx, uri, result.Root)
+	c.wg.Wait()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	result.link()
+
+	return result, nil
+}
@@ -0,0 +1,208 @@
+package cinp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type testDescribe struct {
+	describeType string
+	describe     Describe
+}
+
+// testAPI is a small API with a namespace, two models and their actions
+func testAPI() map[string]testDescribe {
+	nameField := FieldParamater{Name: "name", Type: "String", Length: 40, Required: true, Mode: "RC"}
+	siteField := FieldParamater{Name: "site", Type: "Model", URI: "/api/v1/Building/Site", Required: true, Mode: "RW"}
+
+	return map[string]testDescribe{
+		"/api/v1/":          {"Namespace", Describe{Name: "root", Path: "/api/v1/", APIVersion: "1.2", MultiURIMax: 2, Namespaces: []string{"/api/v1/Building/"}, Models: []string{}}},
+		"/api/v1/Building/": {"Namespace", Describe{Name: "Building", Doc: "Buildings and Rooms", Path: "/api/v1/Building/", APIVersion: "1.2", MultiURIMax: 2, Namespaces: []string{}, Models: []string{"/api/v1/Building/Site", "/api/v1/Building/Room"}}},
+		"/api/v1/Building/Site": {"Model", Describe{
+			Name:              "Site",
+			Doc:               "A Site",
+			Path:              "/api/v1/Building/Site",
+			Constants:         map[string]string{},
+			Fields:            []FieldParamater{nameField, {Name: "description", Type: "String", Length: 255, Mode: "RW"}, {Name: "created", Type: "DateTime", Mode: "RO"}},
+			Actions:           []string{"/api/v1/Building/Site(summary)"},
+			NotAllowedMethods: []string{},
+			ListFilters:       map[string][]FieldParamater{"name": {nameField}},
+		}},
+		"/api/v1/Building/Room": {"Model", Describe{
+			Name:              "Room",
+			Doc:               "A Room",
+			Path:              "/api/v1/Building/Room",
+			Constants:         map[string]string{"MAX_SIZE": "100"},
+			Fields:            []FieldParamater{nameField, siteField, {Name: "size", Type: "Integer", Mode: "RW"}, {Name: "kind", Type: "String", Length: 10, Choices: []interface{}{"office", "lab"}, Mode: "RW"}},
+			Actions:           []string{"/api/v1/Building/Room(move)"},
+			NotAllowedMethods: []string{"DELETE"},
+			ListFilters:       map[string][]FieldParamater{"site": {siteField}},
+		}},
+		"/api/v1/Building/Site(summary)": {"Action", Describe{
+			Name:       "summary",
+			Path:       "/api/v1/Building/Site(summary)",
+			Static:     true,
+			ReturnType: FieldParamater{Type: "Map"},
+			Paramaters: []FieldParamater{},
+		}},
+		"/api/v1/Building/Room(move)": {"Action", Describe{
+			Name:       "move",
+			Path:       "/api/v1/Building/Room(move)",
+			Static:     false,
+			ReturnType: FieldParamater{Type: "Boolean"},
+			Paramaters: []FieldParamater{{Name: "site", Type: "Model", URI: "/api/v1/Building/Site", Required: true}, {Name: "note", Type: "String", Length: 20}},
+		}},
+	}
+}
+
+// newTestAPIServer serves DESCRIBE for api, other requests are passed to handler if it is not nil
+func newTestAPIServer(api map[string]testDescribe, handler http.HandlerFunc) *httptest.Server {
+	var mutex sync.Mutex
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != "DESCRIBE" {
+			if handler == nil {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			handler(rw, req)
+			return
+		}
+
+		mutex.Lock()
+		item, ok := api[req.URL.Path]
+		mutex.Unlock()
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rw.Header().Set("Type", item.describeType)
+		json.NewEncoder(rw).Encode(item.describe)
+	}))
+}
+
+func TestCrawl(t *testing.T) {
+	server := newTestAPIServer(testAPI(), nil)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	for _, parallelism := range []int{0, 1, 4} {
+		schema, err := c.Crawl(context.TODO(), "/api/v1/", parallelism)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+
+		if schema.Root.APIVersion != "1.2" || len(schema.Root.Namespaces) != 1 || schema.Root.Namespaces[0].Name != "Building" {
+			t.Errorf("Root namespace wrong, got '%+v'", schema.Root)
+			t.FailNow()
+		}
+
+		models := []string{}
+		for _, model := range schema.Models() {
+			models = append(models, model.Path)
+		}
+		if !reflect.DeepEqual(models, []string{"/api/v1/Building/Site", "/api/v1/Building/Room"}) {
+			t.Errorf("Expected Site and Room got '%s'", models)
+			t.FailNow()
+		}
+
+		site := schema.Model("/api/v1/Building/Site")
+		room := schema.Model("/api/v1/Building/Room")
+		if site == nil || room == nil {
+			t.Errorf("Model missing")
+			t.FailNow()
+		}
+
+		if room.Fields[1].Name != "site" || room.Fields[1].Model != site {
+			t.Errorf("Room.site not linked to Site")
+			t.FailNow()
+		}
+		if room.ListFilters["site"][0].Model != site {
+			t.Errorf("Room site filter not linked to Site")
+			t.FailNow()
+		}
+		if room.Fields[0].Model != nil {
+			t.Errorf("String field linked to a model")
+			t.FailNow()
+		}
+		if room.Constants["MAX_SIZE"] != "100" {
+			t.Errorf("Constants missing")
+			t.FailNow()
+		}
+
+		if len(room.Actions) != 1 || room.Actions[0].Name != "move" || room.Actions[0].Static || room.Actions[0].Paramaters[0].Model != site {
+			t.Errorf("Room actions wrong, got '%+v'", room.Actions)
+			t.FailNow()
+		}
+		if len(site.Actions) != 1 || !site.Actions[0].Static || site.Actions[0].ReturnType.Type != "Map" {
+			t.Errorf("Site actions wrong, got '%+v'", site.Actions)
+			t.FailNow()
+		}
+	}
+
+	// crawling a sub namespace
+	schema, err := c.Crawl(context.TODO(), "/api/v1/Building/", 2)
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if schema.Root.Path != "/api/v1/Building/" || len(schema.Models()) != 2 {
+		t.Errorf("Sub namespace crawl wrong, got '%+v'", schema.Root)
+		t.FailNow()
+	}
+
+	// not a namespace
+	_, err = c.Crawl(context.TODO(), "/api/v1/Building/Site", 2)
+	if err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestCrawlError(t *testing.T) {
+	api := testAPI()
+	delete(api, "/api/v1/Building/Room(move)")
+	server := newTestAPIServer(api, nil)
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	_, err = c.Crawl(context.TODO(), "/api/v1/", 3)
+	if err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestSchemaName(t *testing.T) {
+	schema := &Schema{Root: &SchemaNamespace{Path: "/api/v1/"}}
+
+	var nameList = map[string]string{
+		"/api/v1/":                       "",
+		"/api/v1/Building/":              "Building",
+		"/api/v1/Building/Site":          "Building.Site",
+		"/api/v1/Building/Site(summary)": "Building.Site.summary",
+		"/api/v1/Building/Sub/Thing":     "Building.Sub.Thing",
+	}
+	for path, name := range nameList {
+		if schema.Name(path) != name {
+			t.Errorf("Expected '%s' got '%s' for '%s'", name, schema.Name(path), path)
+			t.FailNow()
+		}
+	}
+}
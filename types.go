@@ -0,0 +1,127 @@
+package cinp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateTimeLayouts are the formats python's isoformat produces, with and with out a timezone
+var dateTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"}
+
+// dateTimeFormat is the format of DateTimes sent to the server, python's isoformat with a timezone
+const dateTimeFormat = "2006-01-02T15:04:05.999999-07:00"
+
+// dateTimeNaiveFormat is python's isoformat with out a timezone
+const dateTimeNaiveFormat = "2006-01-02T15:04:05.999999"
+
+// parseDateTime parses a CInP DateTime, values with out a timezone are UTC
+func parseDateTime(value string) (time.Time, error) {
+	result, _, err := parseDateTimeNaive(value)
+	return result, err
+}
+
+// parseDateTimeNaive parses a CInP DateTime, naive is true if the value has no timezone
+func parseDateTimeNaive(value string) (time.Time, bool, error) {
+	for i, layout := range dateTimeLayouts {
+		if result, err := time.Parse(layout, value); err == nil {
+			return result, i > 0, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("unable to parse DateTime '%s'", value)
+}
+
+// DateTime is for DateTime fields of registered types, it encodes and decodes the format of python's isoformat.
+// Values with out a timezone are decoded as UTC with Naive set, and encoded with out a timezone when Naive is
+// set.  The zero DateTime is null.
+type DateTime struct {
+	time.Time
+	Naive bool
+}
+
+// NewDateTime returns a DateTime with a timezone for t
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{Time: t}
+}
+
+// String returns the DateTime the way it is sent to the server
+func (d DateTime) String() string {
+	if d.Naive {
+		return d.Time.UTC().Format(dateTimeNaiveFormat)
+	}
+
+	return d.Time.Format(dateTimeFormat)
+}
+
+// MarshalJSON encodes the DateTime, or null if it is the zero DateTime
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.Time.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes the DateTime, null is the zero DateTime
+func (d *DateTime) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	*d = DateTime{}
+	if value == nil {
+		return nil
+	}
+
+	var err error
+	d.Time, d.Naive, err = parseDateTimeNaive(*value)
+
+	return err
+}
+
+// Map is for Map fields of registered types, numbers are decoded as json.Number so integers keep their
+// precision
+type Map map[string]interface{}
+
+// UnmarshalJSON decodes the Map with UseNumber
+func (m *Map) UnmarshalJSON(data []byte) error {
+	var result map[string]interface{}
+	if err := decodeJSON(data, &result); err != nil {
+		return err
+	}
+	*m = result
+
+	return nil
+}
+
+// Float is for Float fields of registered types, it is always encoded with a decimal point so the server does
+// not see a Integer, and decodes Integers
+type Float float64
+
+// MarshalJSON encodes the Float with a decimal point
+func (f Float) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return nil, fmt.Errorf("Float value '%v' can not be encoded", float64(f))
+	}
+
+	result := strconv.FormatFloat(float64(f), 'f', -1, 64)
+	if !strings.Contains(result, ".") {
+		result += ".0"
+	}
+
+	return []byte(result), nil
+}
+
+// decodeJSON decodes data into target with UseNumber
+func decodeJSON(data []byte, target interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(target)
+}
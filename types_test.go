@@ -0,0 +1,117 @@
+package cinp
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDateTime(t *testing.T) {
+	tests := map[string]time.Time{
+		"2024-01-02T03:04:05":              time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"2024-01-02T03:04:05.123456":       time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC),
+		"2024-01-02T03:04:05+00:00":        time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"2024-01-02T03:04:05.5-07:00":      time.Date(2024, 1, 2, 10, 4, 5, 500000000, time.UTC),
+		"2024-01-02T03:04:05.123456+01:00": time.Date(2024, 1, 2, 2, 4, 5, 123456000, time.UTC),
+	}
+	for value, expected := range tests {
+		result, err := parseDateTime(value)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if !result.Equal(expected) {
+			t.Errorf("Expected '%s' got '%s' for '%s'", expected, result, value)
+			t.FailNow()
+		}
+	}
+
+	if _, err := parseDateTime("yesterday"); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	type value struct {
+		When DateTime `json:"when"`
+	}
+
+	tests := map[string]DateTime{
+		`{"when":"2024-01-02T03:04:05"}`:              {Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Naive: true},
+		`{"when":"2024-01-02T03:04:05.123456"}`:       {Time: time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC), Naive: true},
+		`{"when":"2024-01-02T03:04:05+00:00"}`:        {Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		`{"when":"2024-01-02T03:04:05.123456-07:00"}`: {Time: time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.FixedZone("", -7*3600))},
+		`{"when":null}`: {},
+	}
+	for data, expected := range tests {
+		result := value{}
+		if err := json.Unmarshal([]byte(data), &result); err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if !result.When.Equal(expected.Time) || result.When.Naive != expected.Naive {
+			t.Errorf("Expected '%v' got '%v' for '%s'", expected, result.When, data)
+			t.FailNow()
+		}
+
+		buff, err := json.Marshal(result)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if string(buff) != data {
+			t.Errorf("Expected '%s' got '%s'", data, buff)
+			t.FailNow()
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"when":"yesterday"}`), &value{}); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+}
+
+func TestMap(t *testing.T) {
+	result := Map{}
+	if err := json.Unmarshal([]byte(`{"id": 9007199254740993, "sub": {"ratio": 1.5}}`), &result); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	expected := Map{"id": json.Number("9007199254740993"), "sub": map[string]interface{}{"ratio": json.Number("1.5")}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected '%v' got '%v'", expected, result)
+		t.FailNow()
+	}
+
+	buff, err := json.Marshal(result)
+	if err != nil || string(buff) != `{"id":9007199254740993,"sub":{"ratio":1.5}}` {
+		t.Errorf("Wrong encoding '%s' '%v'", buff, err)
+		t.FailNow()
+	}
+}
+
+func TestFloat(t *testing.T) {
+	tests := map[Float]string{1: "1.0", 1.5: "1.5", -2: "-2.0", 0: "0.0", 1e21: "1000000000000000000000.0"}
+	for value, expected := range tests {
+		buff, err := json.Marshal(value)
+		if err != nil || string(buff) != expected {
+			t.Errorf("Expected '%s' got '%s' '%v'", expected, buff, err)
+			t.FailNow()
+		}
+	}
+
+	if _, err := json.Marshal(Float(math.NaN())); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	var result Float
+	if err := json.Unmarshal([]byte("3"), &result); err != nil || result != 3 {
+		t.Errorf("Wrong decode '%v' '%v'", result, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,201 @@
+package cinp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// InvalidValue is a error that is returned when a value does not match it's Field or Paramater
+type InvalidValue struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidValue) Error() string {
+	return fmt.Sprintf("invalid value for '%s': %s", e.Name, e.Reason)
+}
+
+// checkValues checks values against fieldList, values for fields not in fieldList and missing required values are errors
+func checkValues(fieldList []FieldParamater, values map[string]interface{}) error {
+	for _, name := range sortedKeys(values, nil) {
+		if err := checkNamedValue(fieldList, name, values[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range fieldList {
+		if _, ok := values[field.Name]; field.Required && !ok {
+			return &InvalidValue{Name: field.Name, Reason: "required"}
+		}
+	}
+
+	return nil
+}
+
+// checkNamedValue checks value against the field in fieldList called name
+func checkNamedValue(fieldList []FieldParamater, name string, value interface{}) error {
+	for _, field := range fieldList {
+		if field.Name == name {
+			return checkValue(field, value)
+		}
+	}
+
+	valid := make([]string, 0, len(fieldList))
+	for _, field := range fieldList {
+		valid = append(valid, field.Name)
+	}
+	sort.Strings(valid)
+
+	return &InvalidValue{Name: name, Reason: fmt.Sprintf("unknown, expected one of '%s'", strings.Join(valid, "', '"))}
+}
+
+// checkValue checks the type, length and choices of value, nil is only allowed for not required fields
+func checkValue(field FieldParamater, value interface{}) error {
+	if value == nil {
+		if field.Required {
+			return &InvalidValue{Name: field.Name, Reason: "required"}
+		}
+		return nil
+	}
+
+	if !field.IsArray {
+		return checkScalar(field, value)
+	}
+
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("expected a list of %s got '%T'", field.Type, value)}
+	}
+
+	for i := 0; i < list.Len(); i++ {
+		if err := checkScalar(field, list.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkScalar(field FieldParamater, value interface{}) error {
+	invalid := func() error {
+		return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("expected a %s got '%T'", field.Type, value)}
+	}
+
+	switch field.Type {
+	case "String", "File":
+		str, ok := value.(string)
+		if !ok {
+			return invalid()
+		}
+		if field.Length > 0 && utf8.RuneCountInString(str) > field.Length {
+			return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("longer than %d", field.Length)}
+		}
+
+	case "Integer":
+		number, ok := toFloat(value)
+		if !ok {
+			return invalid()
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("'%v' is not a finite number", value)}
+		}
+		if number != math.Trunc(number) {
+			return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("'%v' is not a whole number", value)}
+		}
+
+	case "Float":
+		number, ok := toFloat(value)
+		if !ok {
+			return invalid()
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) { // JSON has no NaN or Inf
+			return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("'%v' is not a finite number", value)}
+		}
+
+	case "Boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid()
+		}
+
+	case "DateTime":
+		switch str := value.(type) {
+		case time.Time, *time.Time, DateTime, *DateTime:
+		case string:
+			if _, err := parseDateTime(str); err != nil {
+				return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("'%s' is not a DateTime", str)}
+			}
+		default:
+			return invalid()
+		}
+
+	case "Map":
+		kind := reflect.Indirect(reflect.ValueOf(value)).Kind()
+		if kind != reflect.Map && kind != reflect.Struct {
+			return invalid()
+		}
+
+	case "Model":
+		var uri string
+		switch ref := value.(type) {
+		case string:
+			uri = ref
+		case ModelRef:
+			uri = ref.URI
+		case *ModelRef:
+			uri = ref.URI
+		default:
+			return invalid()
+		}
+		if !strings.HasPrefix(uri, field.URI+":") {
+			return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("'%s' is not a URI of '%s'", uri, field.URI)}
+		}
+	}
+
+	if len(field.Choices) > 0 {
+		for _, choice := range field.Choices {
+			if sameValue(choice, value) {
+				return nil
+			}
+		}
+		return &InvalidValue{Name: field.Name, Reason: fmt.Sprintf("'%v' is not one of %v", value, field.Choices)}
+	}
+
+	return nil
+}
+
+// toFloat converts go and json numbers to float64
+func toFloat(value interface{}) (float64, bool) {
+	if number, ok := value.(json.Number); ok {
+		result, err := number.Float64()
+		return result, err == nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+// sameValue compares values the way they would be compared after a trip through JSON, ie int(1) == float64(1)
+func sameValue(a interface{}, b interface{}) bool {
+	aNumber, aOk := toFloat(a)
+	bNumber, bOk := toFloat(b)
+	if aOk && bOk {
+		return aNumber == bNumber
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,98 @@
+package cinp
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCheckValue(t *testing.T) {
+	tests := []struct {
+		field FieldParamater
+		value interface{}
+		ok    bool
+	}{
+		{FieldParamater{Name: "a", Type: "String"}, "hello", true},
+		{FieldParamater{Name: "a", Type: "String"}, 1, false},
+		{FieldParamater{Name: "a", Type: "String", Length: 5}, "hello", true},
+		{FieldParamater{Name: "a", Type: "String", Length: 5}, "héllo", true},
+		{FieldParamater{Name: "a", Type: "String", Length: 4}, "hello", false},
+		{FieldParamater{Name: "a", Type: "String"}, nil, true},
+		{FieldParamater{Name: "a", Type: "String", Required: true}, nil, false},
+		{FieldParamater{Name: "a", Type: "Integer"}, 1, true},
+		{FieldParamater{Name: "a", Type: "Integer"}, uint8(1), true},
+		{FieldParamater{Name: "a", Type: "Integer"}, 2.0, true},
+		{FieldParamater{Name: "a", Type: "Integer"}, 2.5, false},
+		{FieldParamater{Name: "a", Type: "Integer"}, json.Number("12"), true},
+		{FieldParamater{Name: "a", Type: "Integer"}, "12", false},
+		{FieldParamater{Name: "a", Type: "Float"}, 2.5, true},
+		{FieldParamater{Name: "a", Type: "Float"}, 2, true},
+		{FieldParamater{Name: "a", Type: "Float"}, true, false},
+		{FieldParamater{Name: "a", Type: "Float"}, math.NaN(), false},
+		{FieldParamater{Name: "a", Type: "Float"}, math.Inf(1), false},
+		{FieldParamater{Name: "a", Type: "Integer"}, math.Inf(-1), false},
+		{FieldParamater{Name: "a", Type: "Integer"}, math.NaN(), false},
+		{FieldParamater{Name: "a", Type: "Boolean"}, false, true},
+		{FieldParamater{Name: "a", Type: "Boolean"}, "true", false},
+		{FieldParamater{Name: "a", Type: "DateTime"}, time.Now(), true},
+		{FieldParamater{Name: "a", Type: "DateTime"}, "2024-01-02T03:04:05", true},
+		{FieldParamater{Name: "a", Type: "DateTime"}, "2024-01-02T03:04:05+00:00", true},
+		{FieldParamater{Name: "a", Type: "DateTime"}, "yesterday", false},
+		{FieldParamater{Name: "a", Type: "DateTime"}, "", false},
+		{FieldParamater{Name: "a", Type: "DateTime"}, 5, false},
+		{FieldParamater{Name: "a", Type: "Map"}, map[string]interface{}{"a": 1}, true},
+		{FieldParamater{Name: "a", Type: "Map"}, &struct{ A int }{}, true},
+		{FieldParamater{Name: "a", Type: "Map"}, []int{}, false},
+		{FieldParamater{Name: "a", Type: "Model", URI: "/api/v1/ns/Model"}, "/api/v1/ns/Model:1:", true},
+		{FieldParamater{Name: "a", Type: "Model", URI: "/api/v1/ns/Model"}, "/api/v1/ns/Other:1:", false},
+		{FieldParamater{Name: "a", Type: "Model", URI: "/api/v1/ns/Model"}, "/api/v1/ns/ModelX:1:", false},
+		{FieldParamater{Name: "a", Type: "Model", URI: "/api/v1/ns/Model"}, 1, false},
+		{FieldParamater{Name: "a", Type: "Model", URI: "/api/v1/ns/Model"}, NewModelRef("/api/v1/ns/Model:1:"), true},
+		{FieldParamater{Name: "a", Type: "Model", URI: "/api/v1/ns/Model"}, &ModelRef{URI: "/api/v1/ns/Other:1:"}, false},
+		{FieldParamater{Name: "a", Type: "String", IsArray: true}, []string{"a", "b"}, true},
+		{FieldParamater{Name: "a", Type: "String", IsArray: true}, []interface{}{"a", 1}, false},
+		{FieldParamater{Name: "a", Type: "String", IsArray: true}, "a", false},
+		{FieldParamater{Name: "a", Type: "String", Choices: []interface{}{"x", "y"}}, "y", true},
+		{FieldParamater{Name: "a", Type: "String", Choices: []interface{}{"x", "y"}}, "z", false},
+		{FieldParamater{Name: "a", Type: "Integer", Choices: []interface{}{1.0, 2.0}}, 2, true},
+		{FieldParamater{Name: "a", Type: "Integer", Choices: []interface{}{1.0, 2.0}}, 3, false},
+	}
+
+	for _, test := range tests {
+		err := checkValue(test.field, test.value)
+		if test.ok && err != nil {
+			t.Errorf("Unexpected error '%s' for %+v '%#v'", err, test.field, test.value)
+			t.FailNow()
+		}
+		if !test.ok {
+			var invalid *InvalidValue
+			if !errors.As(err, &invalid) {
+				t.Errorf("Expected InvalidValue for %+v '%#v' got '%v'", test.field, test.value, err)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestCheckValues(t *testing.T) {
+	fieldList := []FieldParamater{{Name: "name", Type: "String", Required: true}, {Name: "size", Type: "Integer"}}
+
+	if err := checkValues(fieldList, map[string]interface{}{"name": "a"}); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	err := checkValues(fieldList, map[string]interface{}{"size": 1})
+	if err == nil || err.Error() != "invalid value for 'name': required" {
+		t.Errorf("Expected required error got '%v'", err)
+		t.FailNow()
+	}
+
+	err = checkValues(fieldList, map[string]interface{}{"name": "a", "colour": "red"})
+	if err == nil || err.Error() != "invalid value for 'colour': unknown, expected one of 'name', 'size'" {
+		t.Errorf("Expected unknown error got '%v'", err)
+		t.FailNow()
+	}
+}
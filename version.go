@@ -0,0 +1,286 @@
+package cinp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// APIVersionMismatch is a error that is returned when a namespace's api-version does not satisfy the client's constraint
+type APIVersionMismatch struct {
+	Namespace  string
+	Version    string
+	Constraint string
+}
+
+func (e *APIVersionMismatch) Error() string {
+	return fmt.Sprintf("API Version '%s' of namespace '%s' does not satisfy '%s'", e.Version, e.Namespace, e.Constraint)
+}
+
+type apiVersion struct {
+	parts      []int
+	prerelease []string
+}
+
+// parseAPIVersion parses semver like versions, ie "1", "1.2", "v1.2.3", "1.2.3-beta.1", build metadata ("+...") is ignored
+func parseAPIVersion(version string) (*apiVersion, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if offset := strings.IndexByte(value, '+'); offset != -1 {
+		value = value[:offset]
+	}
+
+	result := &apiVersion{}
+	if offset := strings.IndexByte(value, '-'); offset != -1 {
+		result.prerelease = strings.Split(value[offset+1:], ".")
+		value = value[:offset]
+		for _, identifier := range result.prerelease {
+			if identifier == "" {
+				return nil, fmt.Errorf("invalid API Version '%s'", version)
+			}
+		}
+	}
+
+	if value == "" {
+		return nil, fmt.Errorf("invalid API Version '%s'", version)
+	}
+
+	for _, part := range strings.Split(value, ".") {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("invalid API Version '%s'", version)
+		}
+		result.parts = append(result.parts, number)
+	}
+
+	return result, nil
+}
+
+func (v *apiVersion) compare(other *apiVersion) int {
+	for i := 0; i < max(len(v.parts), len(other.parts)); i++ { // missing parts are 0, so "1.2" == "1.2.0"
+		var a, b int
+		if i < len(v.parts) {
+			a = v.parts[i]
+		}
+		if i < len(other.parts) {
+			b = other.parts[i]
+		}
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// a pre-release is before the release
+	if len(v.prerelease) == 0 || len(other.prerelease) == 0 {
+		if len(v.prerelease) == len(other.prerelease) {
+			return 0
+		}
+		if len(v.prerelease) == 0 {
+			return 1
+		}
+		return -1
+	}
+
+	for i := 0; i < min(len(v.prerelease), len(other.prerelease)); i++ {
+		a, b := v.prerelease[i], other.prerelease[i]
+		aNumber, aErr := strconv.Atoi(a)
+		bNumber, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNumber != bNumber {
+				if aNumber < bNumber {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil: // numeric identifiers are before alphanumeric ones
+			return -1
+		case bErr == nil:
+			return 1
+		case a != b:
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(v.prerelease) < len(other.prerelease):
+		return -1
+	case len(v.prerelease) > len(other.prerelease):
+		return 1
+	}
+
+	return 0
+}
+
+// CompareAPIVersion compares two api-version strings semver style, returns -1 if a < b, 0 if a == b and 1 if a > b
+func CompareAPIVersion(a string, b string) (int, error) {
+	aVersion, err := parseAPIVersion(a)
+	if err != nil {
+		return 0, err
+	}
+
+	bVersion, err := parseAPIVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return aVersion.compare(bVersion), nil
+}
+
+type apiVersionComparator struct {
+	operator string
+	version  *apiVersion
+}
+
+// parseAPIVersionConstraint parses a comma seperated list of comparisons, ie ">=1.2, <2", a version with out a operator is "="
+func parseAPIVersionConstraint(constraint string) ([]apiVersionComparator, error) {
+	result := []apiVersionComparator{}
+	for _, item := range strings.Split(constraint, ",") {
+		item = strings.TrimSpace(item)
+		operator := "="
+		for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(item, op) {
+				operator = op
+				item = strings.TrimSpace(item[len(op):])
+				break
+			}
+		}
+
+		version, err := parseAPIVersion(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API Version constraint '%s': %w", constraint, err)
+		}
+
+		result = append(result, apiVersionComparator{operator: operator, version: version})
+	}
+
+	return result, nil
+}
+
+func checkAPIVersionConstraint(comparatorList []apiVersionComparator, version string) (bool, error) {
+	value, err := parseAPIVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	for _, comparator := range comparatorList {
+		result := value.compare(comparator.version)
+		var ok bool
+		switch comparator.operator {
+		case ">=":
+			ok = result >= 0
+		case "<=":
+			ok = result <= 0
+		case "!=":
+			ok = result != 0
+		case ">":
+			ok = result > 0
+		case "<":
+			ok = result < 0
+		default:
+			ok = result == 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// SetAPIVersionConstraint sets the range of api-versions the client supports, ie ">=1.2, <2".  The first time a
+// namespace is used, it's api-version is checked against the constraint, requests into a namespace that does
+// not satisfy the constraint fail with a APIVersionMismatch error.  Namespaces with out a api-version are not
+// checked.  A constraint of "" disables the check.
+func (cinp *CInP) SetAPIVersionConstraint(constraint string) error {
+	var comparatorList []apiVersionComparator
+	if constraint != "" {
+		var err error
+		comparatorList, err = parseAPIVersionConstraint(constraint)
+		if err != nil {
+			return err
+		}
+	}
+
+	cinp.log.Debug("Set API Version Constraint", "constraint", constraint)
+
+	cinp.apiVersionMutex.Lock()
+	defer cinp.apiVersionMutex.Unlock()
+
+	cinp.apiVersionConstraint = constraint
+	cinp.apiVersionComparators = comparatorList
+	cinp.apiVersions = map[string]string{}
+
+	return nil
+}
+
+func (cinp *CInP) apiVersionConstrained() bool {
+	cinp.apiVersionMutex.Lock()
+	defer cinp.apiVersionMutex.Unlock()
+
+	return cinp.apiVersionComparators != nil
+}
+
+// APIVersion returns the api-version of the namespace, as negotiated the first time the namespace was used,
+// "" if the namespace has not been checked
+func (cinp *CInP) APIVersion(namespace string) string {
+	cinp.apiVersionMutex.Lock()
+	defer cinp.apiVersionMutex.Unlock()
+
+	return cinp.apiVersions[namespace]
+}
+
+// CheckAPIVersion returns the api-version of the namespace the uri is in, checking it against the constraint
+// if it has not already been checked.  Namespaces with out a api-version are not checked.
+func (cinp *CInP) CheckAPIVersion(ctx context.Context, uri string) (string, error) {
+	u := cinp.uriFor(uri)
+	ns, _, _, _, _, err := u.Split(uri)
+	if err != nil {
+		return "", err
+	}
+	namespace, err := u.Build(ns, "", "", nil)
+	if err != nil {
+		return "", err
+	}
+
+	cinp.apiVersionMutex.Lock()
+	version, ok := cinp.apiVersions[namespace]
+	constraint := cinp.apiVersionConstraint
+	comparatorList := cinp.apiVersionComparators
+	cinp.apiVersionMutex.Unlock()
+
+	if !ok {
+		describe, _, err := cinp.Describe(ctx, namespace)
+		if err != nil {
+			return "", err
+		}
+		version = describe.APIVersion
+
+		cinp.log.Debug("API Version", "namespace", namespace, "version", version)
+
+		cinp.apiVersionMutex.Lock()
+		cinp.apiVersions[namespace] = version
+		cinp.apiVersionMutex.Unlock()
+	}
+
+	if comparatorList == nil || version == "" { // a namespace with out a api-version is not checked
+		return version, nil
+	}
+
+	ok, err = checkAPIVersionConstraint(comparatorList, version)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", &APIVersionMismatch{Namespace: namespace, Version: version, Constraint: constraint}
+	}
+
+	return version, nil
+}
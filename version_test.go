@@ -0,0 +1,213 @@
+package cinp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCompareAPIVersion(t *testing.T) {
+	var compareList = []struct {
+		a      string
+		b      string
+		result int
+	}{
+		{"1", "1", 0},
+		{"1.0", "1", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.2", "1.2", 0},
+		{"1.2+build5", "1.2", 0},
+		{"1.2", "1.10", -1},
+		{"1.10", "1.2", 1},
+		{"2", "1.99.99", 1},
+		{"0.1", "0.2", -1},
+		{"1.0-beta", "1.0", -1},
+		{"1.0", "1.0-beta", 1},
+		{"1.0-alpha", "1.0-beta", -1},
+		{"1.0-alpha.1", "1.0-alpha", 1},
+		{"1.0-alpha.2", "1.0-alpha.10", -1},
+		{"1.0-1", "1.0-alpha", -1},
+		{"1.0-rc.1", "1.0-rc.1", 0},
+	}
+	for _, v := range compareList {
+		result, err := CompareAPIVersion(v.a, v.b)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if result != v.result {
+			t.Errorf("Expected %d got %d for '%s' and '%s'", v.result, result, v.a, v.b)
+			t.FailNow()
+		}
+	}
+
+	for _, v := range []string{"", "v", "a.b", "1..2", "1.-2", "1.0-", "1.0-a..b", "1.2.x"} {
+		_, err := CompareAPIVersion(v, "1.0")
+		if err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+	}
+}
+
+func TestAPIVersionConstraint(t *testing.T) {
+	var constraintList = []struct {
+		constraint string
+		version    string
+		result     bool
+	}{
+		{"1.2", "1.2", true},
+		{"1.2", "1.2.1", false},
+		{"=1.2", "1.2.0", true},
+		{">=1.2, <2", "1.2", true},
+		{">=1.2, <2", "1.9.9", true},
+		{">=1.2, <2", "2.0", false},
+		{">=1.2, <2", "1.1", false},
+		{">1.2", "1.2", false},
+		{"<=1.2", "1.2", true},
+		{"!=1.3", "1.3", false},
+		{"!=1.3", "1.4", true},
+	}
+	for _, v := range constraintList {
+		comparatorList, err := parseAPIVersionConstraint(v.constraint)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		result, err := checkAPIVersionConstraint(comparatorList, v.version)
+		if err != nil {
+			t.Errorf("Unexpected error '%s'", err)
+			t.FailNow()
+		}
+		if result != v.result {
+			t.Errorf("Expected %t got %t for '%s' and '%s'", v.result, result, v.constraint, v.version)
+			t.FailNow()
+		}
+	}
+
+	for _, v := range []string{">=", ">=1.2,", "~1.2", ">=1.2 <2"} {
+		_, err := parseAPIVersionConstraint(v)
+		if err == nil {
+			t.Errorf("error missing for '%s'", v)
+			t.FailNow()
+		}
+	}
+}
+
+func TestAPIVersionCheck(t *testing.T) {
+	api := testAPI()
+	building := api["/api/v1/Building/"]
+	building.describe.APIVersion = "2.1"
+	api["/api/v1/Building/"] = building
+
+	requestCount := 0
+	server := newTestAPIServer(api, func(rw http.ResponseWriter, req *http.Request) {
+		requestCount++
+		rw.Write([]byte("{}"))
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := c.SetAPIVersionConstraint("bad"); err == nil {
+		t.Errorf("error missing")
+		t.FailNow()
+	}
+
+	if err := c.SetAPIVersionConstraint(">=1.0, <2"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	result := map[string]interface{}{}
+	if err := c.Call(context.TODO(), "/api/v1/Auth(ping)", &map[string]interface{}{}, &result); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if c.APIVersion("/api/v1/") != "1.2" {
+		t.Errorf("Expected '1.2' got '%s'", c.APIVersion("/api/v1/"))
+		t.FailNow()
+	}
+
+	_, err = c.Get(context.TODO(), "/api/v1/Building/Site:1:")
+	mismatch := &APIVersionMismatch{}
+	if !errors.As(err, &mismatch) {
+		t.Errorf("Expected APIVersionMismatch got '%s'", err)
+		t.FailNow()
+	}
+	if mismatch.Namespace != "/api/v1/Building/" || mismatch.Version != "2.1" || mismatch.Constraint != ">=1.0, <2" {
+		t.Errorf("APIVersionMismatch wrong, got '%+v'", mismatch)
+		t.FailNow()
+	}
+	if requestCount != 1 {
+		t.Errorf("Expected 1 request got %d", requestCount)
+		t.FailNow()
+	}
+
+	// with out a constraint, requests are not checked
+	if err := c.SetAPIVersionConstraint(""); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if _, err = c.Get(context.TODO(), "/api/v1/Building/Site:1:"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if c.APIVersion("/api/v1/Building/") != "" {
+		t.Errorf("Expected '' got '%s'", c.APIVersion("/api/v1/Building/"))
+		t.FailNow()
+	}
+
+	version, err := c.CheckAPIVersion(context.TODO(), "/api/v1/Building/Room:4:(move)")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if version != "2.1" || c.APIVersion("/api/v1/Building/") != "2.1" {
+		t.Errorf("Expected '2.1' got '%s'", version)
+		t.FailNow()
+	}
+}
+
+func TestAPIVersionCheckEmpty(t *testing.T) {
+	api := testAPI()
+	building := api["/api/v1/Building/"]
+	building.describe.APIVersion = ""
+	api["/api/v1/Building/"] = building
+
+	server := newTestAPIServer(api, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("{}"))
+	})
+	defer server.Close()
+
+	c, err := NewCInP(getLogger(), server.URL, "/api/v1/", "")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if err := c.SetAPIVersionConstraint(">=1.0, <2"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	if _, err = c.Get(context.TODO(), "/api/v1/Building/Site:1:"); err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+
+	version, err := c.CheckAPIVersion(context.TODO(), "/api/v1/Building/Site:1:")
+	if err != nil {
+		t.Errorf("Unexpected error '%s'", err)
+		t.FailNow()
+	}
+	if version != "" {
+		t.Errorf("Expected '' got '%s'", version)
+		t.FailNow()
+	}
+}